@@ -0,0 +1,104 @@
+package circuitbreaker
+
+import (
+	"errors"
+	"math/rand"
+	"sync"
+)
+
+// ErrTooManyRequests is returned by Execute/Do when the circuit is HalfOpen
+// and the call is rejected by the configured admission controls
+// (SetHalfOpenMaxRequests or SetHalfOpenAdmitProbability) rather than by the
+// error rate itself.
+var ErrTooManyRequests = errors.New("circuitbreaker: too many requests")
+
+// IRand abstracts the random source used for probabilistic HalfOpen
+// admission, following the same pattern as ITime: production code uses Rand,
+// tests can substitute a deterministic implementation.
+type IRand interface {
+	Float64() float64
+}
+
+type Rand struct{}
+
+func (Rand) Float64() float64 {
+	return rand.Float64()
+}
+
+// UNSAFESetRand allows setting a custom random source for the circuit
+// breaker. This is particularly(only?) useful for unit testing
+// SetHalfOpenAdmitProbability deterministically.
+func (b *Builder) UNSAFESetRand(r IRand) *Builder {
+	b.cb.rand = r
+	return b
+}
+
+// SetHalfOpenMaxRequests caps the number of trial calls admitted while the
+// circuit is HalfOpen to n. Once n calls have been admitted without their
+// outcome being recorded yet, further calls are rejected with
+// ErrTooManyRequests until an admitted call completes.
+//
+// If not set, or set to 0, the number of in-flight trial calls is unbounded.
+func (b *Builder) SetHalfOpenMaxRequests(n int) *Builder {
+	b.cb.config.HalfOpenMaxRequests = n
+	return b
+}
+
+// SetHalfOpenAdmitProbability admits each HalfOpen trial call with
+// probability p (0.0-1.0), rejecting the rest with ErrTooManyRequests without
+// incrementing any in-flight counters. This mirrors Overcurrent's
+// HalfClosedRetryProbability and is most useful combined with
+// SetHalfOpenMaxRequests to thin out trial traffic further.
+//
+// If not set, the default probability is 1.0 (every call within the
+// HalfOpenMaxRequests cap is admitted).
+func (b *Builder) SetHalfOpenAdmitProbability(p float64) *Builder {
+	b.cb.config.HalfOpenAdmitProbability = p
+	return b
+}
+
+// admitLocked decides whether a call should be allowed to run given the
+// current state, applying HalfOpen admission control. The caller must
+// already hold cb.mu.
+//
+// On admission into a HalfOpen trial, it increments halfOpenInFlight and
+// returns a release func that decrements it again exactly once, however the
+// call completes (success, failure, panic, or the circuit changing state out
+// from under it before the call finishes). The caller must defer release
+// immediately, rather than relying on whatever Record/record does with the
+// call's eventual outcome - a straggler call that finishes after the circuit
+// has already moved HalfOpen->Open must still free its slot, or the slot
+// leaks for the lifetime of the breaker. When no slot was reserved (the
+// circuit isn't HalfOpen, or the call is rejected), release is a no-op.
+func (cb *CircuitBreaker) admitLocked() (release func(), err error) {
+	if cb.state == Open {
+		return noopRelease, ErrCircuitOpen
+	}
+
+	if cb.state != HalfOpen {
+		return noopRelease, nil
+	}
+
+	if cb.config.HalfOpenMaxRequests > 0 && cb.halfOpenInFlight >= cb.config.HalfOpenMaxRequests {
+		return noopRelease, ErrTooManyRequests
+	}
+
+	if cb.config.HalfOpenAdmitProbability < 1.0 && cb.rand.Float64() >= cb.config.HalfOpenAdmitProbability {
+		return noopRelease, ErrTooManyRequests
+	}
+
+	cb.halfOpenInFlight++
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			cb.mu.Lock()
+			defer cb.mu.Unlock()
+			if cb.halfOpenInFlight > 0 {
+				cb.halfOpenInFlight--
+			}
+		})
+	}, nil
+}
+
+func noopRelease() {}
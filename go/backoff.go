@@ -0,0 +1,138 @@
+package circuitbreaker
+
+import (
+	"math"
+	"math/rand"
+	"time"
+
+	"circuitbreaker/internal/scheduler"
+)
+
+// Backoff computes how long a circuit breaker should wait in the Open state
+// before transitioning to HalfOpen, given how many consecutive times in a
+// row the circuit has regressed from HalfOpen back to Open. This replaces a
+// single fixed SetRetryTimeout duration with a strategy that can back off
+// further each time a trial fails, avoiding every dependent retrying at
+// exactly the same moment (a thundering herd).
+type Backoff interface {
+	// Next returns the duration to wait before the next HalfOpen trial.
+	// attempt is 0 on the first trip, and increments for each consecutive
+	// HalfOpen->Open regression since the circuit last closed successfully.
+	Next(attempt int) time.Duration
+	// Reset is called once the circuit closes again, so stateful strategies
+	// (e.g. ones that cache a random source) can start over.
+	Reset()
+}
+
+// ConstantBackoff always waits the same duration, reproducing the circuit
+// breaker's original SetRetryTimeout behavior.
+type ConstantBackoff time.Duration
+
+func (c ConstantBackoff) Next(attempt int) time.Duration { return time.Duration(c) }
+func (c ConstantBackoff) Reset()                         {}
+
+// ExponentialBackoff waits base * factor^attempt, capped at max.
+type ExponentialBackoff struct {
+	Base   time.Duration
+	Max    time.Duration
+	Factor float64
+}
+
+func (e ExponentialBackoff) Next(attempt int) time.Duration {
+	d := time.Duration(float64(e.Base) * math.Pow(e.Factor, float64(attempt)))
+	if d > e.Max || d <= 0 {
+		return e.Max
+	}
+	return d
+}
+
+func (e ExponentialBackoff) Reset() {}
+
+// JitteredBackoff wraps another Backoff and randomizes its result by up to
+// +/- ratio (0.0-1.0) of the inner duration, so that dependents computing the
+// same attempt number don't all retry at exactly the same moment.
+type JitteredBackoff struct {
+	Inner Backoff
+	Ratio float64
+}
+
+func (j JitteredBackoff) Next(attempt int) time.Duration {
+	base := j.Inner.Next(attempt)
+	if j.Ratio <= 0 {
+		return base
+	}
+
+	jitter := (rand.Float64()*2 - 1) * j.Ratio
+	d := time.Duration(float64(base) * (1 + jitter))
+	if d < 0 {
+		return 0
+	}
+	return d
+}
+
+func (j JitteredBackoff) Reset() {
+	j.Inner.Reset()
+}
+
+// SetBackoff configures the strategy used to compute how long the circuit
+// breaker waits in the Open state before transitioning to HalfOpen. attempt,
+// passed to Backoff.Next, increments every consecutive HalfOpen->Open
+// regression and resets to 0 once the circuit closes again.
+//
+// If not set, the default is ConstantBackoff(RetryTimeout) - i.e. the
+// original fixed-delay behavior configured via SetRetryTimeout.
+func (b *Builder) SetBackoff(backoff Backoff) *Builder {
+	b.cb.backoff = backoff
+	return b
+}
+
+// UNSAFEGetRetryDeadline returns the instant the retry scheduler is expected
+// to move the circuit from Open to HalfOpen, per the currently configured
+// Backoff. It is the zero Time if the circuit has never tripped Open.
+//
+// UNSAFE - only intended for use by internal tooling such as the vis UI.
+func (cb *CircuitBreaker) UNSAFEGetRetryDeadline() time.Time {
+	cb.mu.RLock()
+	defer cb.mu.RUnlock()
+	return cb.retryDeadline
+}
+
+func (cb *CircuitBreaker) backoffLocked() Backoff {
+	if cb.backoff != nil {
+		return cb.backoff
+	}
+	return ConstantBackoff(cb.config.RetryTimeout)
+}
+
+// startRetrySchedulerLocked computes the next retry duration from the
+// configured Backoff and (re)starts the retry scheduler with it. The caller
+// must already hold cb.mu.
+func (cb *CircuitBreaker) startRetrySchedulerLocked() {
+	cb.probeFailures = 0
+	cb.probeLastFailureAt = time.Time{}
+
+	if cb.healthProbe != nil {
+		cb.startHealthProbeLocked()
+		return
+	}
+
+	duration := cb.backoffLocked().Next(cb.attempt)
+	cb.attempt++
+	cb.retryDuration = duration
+	cb.retryDeadline = cb.time.Now().Add(duration)
+
+	cb.retryScheduler = scheduler.New(cb.time, duration, func() {
+		cb.mu.Lock()
+		defer cb.mu.Unlock()
+
+		if cb.state != Open {
+			return
+		}
+
+		from := cb.state
+		cb.state = HalfOpen
+		cb.retryScheduler.Stop()
+		cb.publishStateChangeReason(from, HalfOpen, RetryElapsed)
+	})
+	cb.retryScheduler.Start()
+}
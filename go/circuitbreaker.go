@@ -49,6 +49,10 @@ type BufferNode struct {
 	Expires      time.Time
 	FailureCount int
 	SuccessCount int
+	// TimeoutCount is the subset of FailureCount that resulted from Execute/Do
+	// exceeding the configured SetCallTimeout, letting Inspect() distinguish
+	// "slow" from "errored".
+	TimeoutCount int
 }
 
 type Status struct {
@@ -65,12 +69,14 @@ func (n *BufferNode) Init(index int) {
 	n.Expires = time.Time{}
 	n.FailureCount = 0
 	n.SuccessCount = 0
+	n.TimeoutCount = 0
 }
 
 func (n *BufferNode) Reset(expires time.Time) {
 	n.Expires = expires
 	n.FailureCount = 0
 	n.SuccessCount = 0
+	n.TimeoutCount = 0
 }
 
 type CircuitBreaker struct {
@@ -86,6 +92,34 @@ type CircuitBreaker struct {
 	// how many consecutive successes have occurred while circuit is HalfOpen
 	trialSuccesses int
 	config         Config
+	// name identifies this breaker in published StateEvents
+	name string
+	// dispatches state-change notifications to OnStateChange callbacks and Subscribe channels
+	events *dispatcher
+	// decides whether a Closed circuit should trip to Open
+	tripPolicy TripPolicy
+	// number of Failure results recorded back to back, reset on the next Success
+	consecutiveFailures int
+	// random source used for SetHalfOpenAdmitProbability
+	rand IRand
+	// number of HalfOpen trial calls admitted but not yet recorded
+	halfOpenInFlight int
+	// computes the Open->HalfOpen delay; defaults to ConstantBackoff(RetryTimeout)
+	backoff Backoff
+	// consecutive HalfOpen->Open regressions since the circuit last closed
+	attempt int
+	// the retry delay computed by backoff for the current Open period
+	retryDuration time.Duration
+	// the instant the retry scheduler is expected to move the circuit to HalfOpen
+	retryDeadline time.Time
+	// configured active health probe; when set, it drives Open->HalfOpen instead of a timer
+	healthProbe *HealthProbe
+	// schedules HealthProbe.Fn calls while Open
+	probeScheduler *scheduler.Scheduler
+	// consecutive failed health probe calls since the circuit last opened
+	probeFailures int
+	// when the most recent health probe call failed
+	probeLastFailureAt time.Time
 }
 type Config struct {
 	// duration of data each node in the buffer stores
@@ -98,6 +132,17 @@ type Config struct {
 	RetryTimeout time.Duration
 	// how many successive successes required to close a half open circuit
 	TrialSuccessesRequired int
+	// classifies an error returned from Execute/Do as Success or Failure.
+	// Defaults to treating a nil error as Success and any other error as Failure.
+	IsSuccessful func(error) bool
+	// caps the number of in-flight HalfOpen trial calls. 0 means unbounded.
+	HalfOpenMaxRequests int
+	// probability (0.0-1.0) that a HalfOpen trial call is admitted. Defaults to 1.0.
+	HalfOpenAdmitProbability float64
+	// maximum duration Execute/Do allows fn to run before treating it as a timed-out Failure. 0 disables the timeout.
+	CallTimeout time.Duration
+	// rotate the sliding window lazily, on each recorded event, instead of via a wall-clock scheduler
+	LazyRotation bool
 }
 
 type Builder struct {
@@ -206,17 +251,28 @@ func (b *Builder) SetTrialSuccessesRequired(number int) *Builder {
 	return b
 }
 
+// SetCallTimeout configures how long Execute/Do allow fn to run, following
+// Mimir's ingester.circuit-breaker.push-timeout pattern: fn is given a
+// context derived with this deadline, and if fn returns because that
+// deadline was exceeded, the call is recorded as a Failure (attributed to
+// BufferNode.TimeoutCount) so operators can trip the breaker on latency
+// degradation rather than only on error responses.
+//
+// If not set, or set to 0, Execute/Do apply no timeout of their own.
+func (b *Builder) SetCallTimeout(d time.Duration) *Builder {
+	b.cb.config.CallTimeout = d
+	return b
+}
+
 func (b *Builder) Build() *CircuitBreaker {
 	b.cb.cursorScheduler = scheduler.New(b.cb.time, b.cb.config.NodeDuration, b.cb.moveCursor)
-	b.cb.retryScheduler = scheduler.New(b.cb.time, b.cb.config.RetryTimeout, func() {
-		b.cb.mu.Lock()
-		defer b.cb.mu.Unlock()
+	// retryScheduler is (re)built by startRetrySchedulerLocked each time the
+	// circuit trips Open, since its duration comes from the configured
+	// Backoff and can change attempt to attempt.
 
-		b.cb.state = HalfOpen
-		b.cb.retryScheduler.Stop()
-	})
-
-	b.cb.cursorScheduler.Start()
+	if !b.cb.config.LazyRotation {
+		b.cb.cursorScheduler.Start()
+	}
 	b.cb.initBuffer()
 	b.cb.buffer.Cursor().Reset(b.cb.time.Now().Add(b.cb.config.NodeDuration))
 
@@ -230,12 +286,16 @@ func New() *Builder {
 			buffer:    ringbuffer.New[BufferNode](DefaultEvalWindow + 1),
 			errorRate: 0.00,
 			time:      &Time{},
+			events:    newDispatcher(),
+			rand:      Rand{},
 			config: Config{
-				NodeDuration:           DefaultNodeDuration,
-				MinEvalSize:            DefaultMinEvalSize,
-				ErrorThreshold:         DefaultErrorThreshold,
-				TrialSuccessesRequired: DefaultTrialSuccessesRequired,
-				RetryTimeout:           DefaultRetryTimeout,
+				NodeDuration:             DefaultNodeDuration,
+				MinEvalSize:              DefaultMinEvalSize,
+				ErrorThreshold:           DefaultErrorThreshold,
+				TrialSuccessesRequired:   DefaultTrialSuccessesRequired,
+				RetryTimeout:             DefaultRetryTimeout,
+				IsSuccessful:             func(err error) bool { return err == nil },
+				HalfOpenAdmitProbability: 1.0,
 			},
 		},
 	}
@@ -245,16 +305,25 @@ func (cb *CircuitBreaker) moveCursor() {
 	cb.mu.Lock()
 	defer cb.mu.Unlock()
 
+	cb.rotateOnceLocked(cb.time.Now())
+}
+
+// rotateOnceLocked advances the buffer to a fresh node expiring at
+// now+NodeDuration, recalculates the error rate, and trips a Closed circuit
+// to Open if the configured TripPolicy says to. The caller must already hold
+// cb.mu. Shared by the wall-clock cursorScheduler and the lazy rotation path.
+func (cb *CircuitBreaker) rotateOnceLocked(now time.Time) {
 	cb.buffer.Next()
-	cb.buffer.Cursor().Reset(cb.time.Now().Add(cb.config.NodeDuration))
+	cb.buffer.Cursor().Reset(now.Add(cb.config.NodeDuration))
 	cb.errorRate = cb.calculateErrorRate(cb.config.MinEvalSize)
 
-	if cb.state == Closed && cb.errorRate > cb.config.ErrorThreshold {
+	if cb.state == Closed && cb.tripPolicyLocked().ShouldTrip(cb.snapshotBufferLocked()) {
 		cb.state = Open
 		cb.clearBuffer()
 		cb.errorRate = 0.00
 		cb.cursorScheduler.Stop()
-		cb.retryScheduler.Start()
+		cb.startRetrySchedulerLocked()
+		cb.publishStateChangeReason(Closed, Open, ThresholdExceeded)
 	}
 }
 
@@ -267,6 +336,7 @@ func (cb *CircuitBreaker) initBuffer() {
 		node.Expires = time.Time{}
 		node.FailureCount = 0
 		node.SuccessCount = 0
+		node.TimeoutCount = 0
 
 		index++
 	})
@@ -277,6 +347,7 @@ func (cb *CircuitBreaker) clearBuffer() {
 		node.Expires = time.Time{}
 		node.FailureCount = 0
 		node.SuccessCount = 0
+		node.TimeoutCount = 0
 	})
 }
 
@@ -287,10 +358,82 @@ func (cb *CircuitBreaker) GetState() State {
 	return cb.state
 }
 
+// GetErrorRate returns the error rate last computed for the evaluation
+// window (0 while Open, since the buffer is cleared on trip).
+func (cb *CircuitBreaker) GetErrorRate() float64 {
+	cb.mu.RLock()
+	defer cb.mu.RUnlock()
+
+	return cb.errorRate
+}
+
+// UNSAFEGetBufferLength reports the number of nodes in the sliding-window
+// ring buffer.
+//
+// UNSAFE - only intended for use by internal tooling such as the vis UI.
+func (cb *CircuitBreaker) UNSAFEGetBufferLength() int {
+	cb.mu.RLock()
+	defer cb.mu.RUnlock()
+
+	return cb.buffer.Len()
+}
+
+// UNSAFEGetCursorIndex reports the ring buffer index of the currently active
+// node.
+//
+// UNSAFE - only intended for use by internal tooling such as the vis UI.
+func (cb *CircuitBreaker) UNSAFEGetCursorIndex() int {
+	cb.mu.RLock()
+	defer cb.mu.RUnlock()
+
+	return cb.buffer.CursorIndex()
+}
+
+// UNSAFEGetCursorByIndex returns the buffer node at index, independent of
+// which node is currently active.
+//
+// UNSAFE - only intended for use by internal tooling such as the vis UI.
+func (cb *CircuitBreaker) UNSAFEGetCursorByIndex(index int) *BufferNode {
+	cb.mu.RLock()
+	defer cb.mu.RUnlock()
+
+	return cb.buffer.At(index)
+}
+
+// UNSAFEGetActiveCursor returns the currently active buffer node.
+//
+// UNSAFE - only intended for use by internal tooling such as the vis UI.
+func (cb *CircuitBreaker) UNSAFEGetActiveCursor() *BufferNode {
+	cb.mu.RLock()
+	defer cb.mu.RUnlock()
+
+	return cb.buffer.Cursor()
+}
+
+// UNSAFEGetTrialState reports the number of consecutive HalfOpen trial
+// successes recorded so far, and how many are required to close the
+// circuit.
+//
+// UNSAFE - only intended for use by internal tooling such as the vis UI.
+func (cb *CircuitBreaker) UNSAFEGetTrialState() (trialSuccesses, trialSuccessesRequired int) {
+	cb.mu.RLock()
+	defer cb.mu.RUnlock()
+
+	return cb.trialSuccesses, cb.config.TrialSuccessesRequired
+}
+
 func (cb *CircuitBreaker) Record(result Result) {
+	cb.record(result, false)
+}
+
+// record is the shared implementation behind Record and the timeout path in
+// Execute/Do. isTimeout additionally attributes a Failure to TimeoutCount.
+func (cb *CircuitBreaker) record(result Result, isTimeout bool) {
 	cb.mu.Lock()
 	defer cb.mu.Unlock()
 
+	cb.lazyRotateLocked(cb.time.Now())
+
 	if cb.state == Open {
 		return
 	}
@@ -302,8 +445,11 @@ func (cb *CircuitBreaker) Record(result Result) {
 		if cb.trialSuccesses >= cb.config.TrialSuccessesRequired {
 			cb.state = Closed
 			cb.trialSuccesses = 0
+			cb.attempt = 0
+			cb.backoffLocked().Reset()
 			cb.cursorScheduler.Start()
 			cb.buffer.Cursor().Reset(cb.time.Now().Add(cb.config.NodeDuration))
+			cb.publishStateChangeReason(HalfOpen, Closed, TrialSuccessesMet)
 		}
 		return
 	}
@@ -313,17 +459,47 @@ func (cb *CircuitBreaker) Record(result Result) {
 	if cb.state == HalfOpen && result == Failure {
 		cb.state = Open
 		cb.trialSuccesses = 0
-		cb.retryScheduler.Start()
+		cb.startRetrySchedulerLocked()
+		cb.publishStateChangeReason(HalfOpen, Open, TrialFailure)
 		return
 	}
 
 	if result == Failure {
 		cb.buffer.Cursor().FailureCount++
+		cb.consecutiveFailures++
+		if isTimeout {
+			cb.buffer.Cursor().TimeoutCount++
+		}
 	} else {
 		cb.buffer.Cursor().SuccessCount++
+		cb.consecutiveFailures = 0
+	}
+
+	// Policies such as ConsecutiveFailuresPolicy are meant to trip promptly,
+	// back-to-back failure by failure, not only once the active node happens
+	// to roll over - so evaluate the trip decision here too, not just in
+	// rotateOnceLocked. A policy that only looks at completed nodes (e.g.
+	// ErrorRatePolicy) simply won't trip here until a rotation populates one.
+	if cb.tripPolicyLocked().ShouldTrip(cb.snapshotBufferLocked()) {
+		cb.state = Open
+		cb.clearBuffer()
+		cb.errorRate = 0.00
+		cb.cursorScheduler.Stop()
+		cb.startRetrySchedulerLocked()
+		cb.publishStateChangeReason(Closed, Open, ThresholdExceeded)
 	}
 }
 
+// tripPolicyLocked returns the configured TripPolicy, falling back to an
+// ErrorRatePolicy built from the current ErrorThreshold/MinEvalSize config
+// when none has been set via SetTripPolicy. The caller must already hold cb.mu.
+func (cb *CircuitBreaker) tripPolicyLocked() TripPolicy {
+	if cb.tripPolicy != nil {
+		return cb.tripPolicy
+	}
+	return ErrorRatePolicy{Threshold: cb.config.ErrorThreshold, MinEvalSize: cb.config.MinEvalSize}
+}
+
 func (cb *CircuitBreaker) calculateErrorRate(minEvalSize int) float64 {
 	failures := 0
 	total := 0
@@ -351,6 +527,12 @@ func (cb *CircuitBreaker) Inspect() *Status {
 	cb.mu.RLock()
 	defer cb.mu.RUnlock()
 
+	return cb.snapshotLocked()
+}
+
+// snapshotLocked builds a Status from the breaker's current fields. Callers
+// must already hold cb.mu (for reading or writing) before calling this.
+func (cb *CircuitBreaker) snapshotLocked() *Status {
 	var bufferNodes []*BufferNode
 
 	cb.buffer.DoFromHead(func(node *BufferNode) {
@@ -372,6 +554,7 @@ func (cb *CircuitBreaker) Inspect() *Status {
 			ErrorThreshold:         cb.config.ErrorThreshold,
 			TrialSuccessesRequired: cb.config.TrialSuccessesRequired,
 			RetryTimeout:           cb.config.RetryTimeout,
+			IsSuccessful:           cb.config.IsSuccessful,
 		},
 	}
 }
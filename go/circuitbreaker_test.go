@@ -1,93 +1,16 @@
 package circuitbreaker_test
 
 import (
+	"context"
+	"errors"
 	"math/rand"
-	"sync"
 	"testing"
 	"time"
 
 	"circuitbreaker"
+	"circuitbreaker/internal/mocktime"
 )
 
-type MockTicker struct {
-	C        chan time.Time
-	duration time.Duration
-	lastTick time.Time
-	mockTime *MockTime
-}
-
-type MockTime struct {
-	MockCurrentTime time.Time
-	tickers         []*MockTicker
-	mu              sync.Mutex
-}
-
-func NewMockTime(initialTime time.Time) *MockTime {
-	return &MockTime{
-		MockCurrentTime: initialTime,
-		tickers:         make([]*MockTicker, 0),
-	}
-}
-
-func (m *MockTime) Now() time.Time {
-	return m.MockCurrentTime
-}
-
-func (m *MockTime) NewTicker(d time.Duration) *time.Ticker {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-
-	mockTicker := &MockTicker{
-		C:        make(chan time.Time, 10),
-		duration: d,
-		lastTick: m.MockCurrentTime,
-		mockTime: m,
-	}
-
-	m.tickers = append(m.tickers, mockTicker)
-
-	return &time.Ticker{
-		C: mockTicker.C,
-	}
-}
-
-func (m *MockTime) removeTicker(ticker *MockTicker) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-
-	for i, t := range m.tickers {
-		if t == ticker {
-			m.tickers = append(m.tickers[:i], m.tickers[i+1:]...)
-			return
-		}
-	}
-}
-
-func (m *MockTime) FastForward(duration time.Duration) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-	m.MockCurrentTime = m.MockCurrentTime.Add(duration)
-
-	for _, ticker := range m.tickers {
-		// Check if, given the duration, the ticker should be fired
-		next := ticker.lastTick.Add(ticker.duration)
-		if next.Before(m.MockCurrentTime) || next.Equal(m.MockCurrentTime) {
-			ticker.C <- ticker.lastTick.Add(ticker.duration)
-			ticker.lastTick = ticker.lastTick.Add(ticker.duration)
-		}
-	}
-
-	// Internally the circuit breaker sets up functions in goroutines that are triggered by ticks.
-	// There is a very small delay between a tick occurs and the callback function runs because we need to wait for Go to schedule the goroutine after the tick occurs
-	// To get around this we add a small delay(not ideal) to give the Go runtime a chance to run the goroutine
-	time.Sleep(1 * time.Millisecond)
-}
-
-func (t *MockTicker) Stop() {
-	t.mockTime.removeTicker(t)
-	close(t.C)
-}
-
 func assert[T comparable](t *testing.T, actual T, expected T) {
 	t.Helper()
 	if actual != expected {
@@ -108,7 +31,7 @@ func RecordSuccesses(num int, cb *circuitbreaker.CircuitBreaker) {
 }
 
 func TestCircuitBreaker(t *testing.T) {
-	mockTime := NewMockTime(time.Now())
+	mockTime := mocktime.NewMockTime(time.Now())
 
 	cb := circuitbreaker.
 		New().
@@ -192,6 +115,393 @@ func TestCircuitBreaker(t *testing.T) {
 	assert(t, circuitbreaker.Closed, status.State)
 }
 
+func TestExecute(t *testing.T) {
+	cb := circuitbreaker.New().Build()
+
+	got, err := circuitbreaker.Execute(context.Background(), cb, func(ctx context.Context) (int, error) {
+		return 42, nil
+	})
+	assert(t, err, nil)
+	assert(t, got, 42)
+
+	boom := errors.New("boom")
+	_, err = circuitbreaker.Execute(context.Background(), cb, func(ctx context.Context) (int, error) {
+		return 0, boom
+	})
+	assert(t, err, boom)
+
+	status := cb.Inspect()
+	assert(t, status.ActiveNode.SuccessCount, 1)
+	assert(t, status.ActiveNode.FailureCount, 1)
+}
+
+func TestExecuteShortCircuitsWhenOpen(t *testing.T) {
+	mockTime := mocktime.NewMockTime(time.Now())
+
+	cb := circuitbreaker.
+		New().
+		UNSAFESetTime(mockTime).
+		SetEvalWindow(1, 1).
+		SetErrorThreshold(10.0).
+		Build()
+
+	RecordSuccesses(200, cb)
+	mockTime.FastForward(61 * time.Second)
+	RecordErrors(250, cb)
+	mockTime.FastForward(61 * time.Second)
+	RecordErrors(1, cb)
+
+	assert(t, circuitbreaker.Open, cb.GetState())
+
+	_, err := circuitbreaker.Execute(context.Background(), cb, func(ctx context.Context) (int, error) {
+		t.Fatal("fn should not be called while the circuit is Open")
+		return 0, nil
+	})
+	assert(t, err, circuitbreaker.ErrCircuitOpen)
+}
+
+func TestSubscribe(t *testing.T) {
+	mockTime := mocktime.NewMockTime(time.Now())
+
+	cb := circuitbreaker.
+		New().
+		UNSAFESetTime(mockTime).
+		SetEvalWindow(1, 1).
+		SetErrorThreshold(10.0).
+		Build()
+
+	events, cancel := cb.Subscribe()
+	defer cancel()
+
+	RecordSuccesses(200, cb)
+	mockTime.FastForward(61 * time.Second)
+	RecordErrors(250, cb)
+	mockTime.FastForward(61 * time.Second)
+	RecordErrors(1, cb)
+
+	select {
+	case event := <-events:
+		assert(t, event.From, circuitbreaker.Closed)
+		assert(t, event.To, circuitbreaker.Open)
+	default:
+		t.Fatal("expected a StateEvent to be published when the circuit opened")
+	}
+}
+
+func TestConsecutiveFailuresPolicy(t *testing.T) {
+	mockTime := mocktime.NewMockTime(time.Now())
+
+	cb := circuitbreaker.
+		New().
+		UNSAFESetTime(mockTime).
+		SetEvalWindow(1, 1).
+		SetTripPolicy(circuitbreaker.ConsecutiveFailuresPolicy(5)).
+		Build()
+
+	RecordErrors(4, cb)
+	mockTime.FastForward(61 * time.Second)
+	assert(t, circuitbreaker.Closed, cb.GetState())
+
+	RecordErrors(1, cb)
+	mockTime.FastForward(61 * time.Second)
+	assert(t, circuitbreaker.Open, cb.GetState())
+}
+
+func TestHalfOpenMaxRequests(t *testing.T) {
+	mockTime := mocktime.NewMockTime(time.Now())
+
+	cb := circuitbreaker.
+		New().
+		UNSAFESetTime(mockTime).
+		SetEvalWindow(1, 1).
+		SetErrorThreshold(10.0).
+		SetRetryTimeout(time.Minute).
+		SetHalfOpenMaxRequests(1).
+		Build()
+
+	RecordSuccesses(200, cb)
+	mockTime.FastForward(61 * time.Second)
+	RecordErrors(250, cb)
+	mockTime.FastForward(61 * time.Second)
+	RecordErrors(1, cb)
+	mockTime.FastForward(61 * time.Second)
+
+	assert(t, circuitbreaker.HalfOpen, cb.GetState())
+
+	_, err := circuitbreaker.Execute(context.Background(), cb, func(ctx context.Context) (int, error) {
+		// admitted trial call; do not record its outcome yet so the slot stays taken
+		_, err := circuitbreaker.Execute(context.Background(), cb, func(ctx context.Context) (int, error) {
+			t.Fatal("second trial call should have been rejected")
+			return 0, nil
+		})
+		assert(t, err, circuitbreaker.ErrTooManyRequests)
+		return 0, nil
+	})
+	assert(t, err, nil)
+}
+
+// TestHalfOpenMaxRequestsReleasesStragglerSlotAfterTrip reproduces a call
+// that is admitted into a HalfOpen trial, is still in flight when a second
+// admitted call fails and trips HalfOpen->Open out from under it, and only
+// completes afterwards. Its admission slot must still be released even
+// though cb.state is Open by the time its own outcome is recorded - the
+// slot's release must not depend on the breaker still being HalfOpen.
+func TestHalfOpenMaxRequestsReleasesStragglerSlotAfterTrip(t *testing.T) {
+	mockTime := mocktime.NewMockTime(time.Now())
+
+	cb := circuitbreaker.
+		New().
+		UNSAFESetTime(mockTime).
+		SetEvalWindow(1, 1).
+		SetErrorThreshold(10.0).
+		SetRetryTimeout(time.Minute).
+		SetHalfOpenMaxRequests(2).
+		Build()
+
+	RecordSuccesses(200, cb)
+	mockTime.FastForward(61 * time.Second)
+	RecordErrors(250, cb)
+	mockTime.FastForward(61 * time.Second)
+	RecordErrors(1, cb)
+	mockTime.FastForward(61 * time.Second)
+
+	assert(t, circuitbreaker.HalfOpen, cb.GetState())
+
+	errBoom := errors.New("boom")
+
+	// The outer Execute call is admitted first (the straggler) and is still
+	// running when the inner call is admitted, fails immediately, and trips
+	// HalfOpen->Open before the outer call returns.
+	_, _ = circuitbreaker.Execute(context.Background(), cb, func(ctx context.Context) (int, error) {
+		_, err := circuitbreaker.Execute(context.Background(), cb, func(ctx context.Context) (int, error) {
+			return 0, errBoom
+		})
+		assert(t, err, errBoom)
+		assert(t, circuitbreaker.Open, cb.GetState())
+		return 0, nil
+	})
+
+	mockTime.FastForward(61 * time.Second)
+	assert(t, circuitbreaker.HalfOpen, cb.GetState())
+
+	// If the straggler's slot had leaked, HalfOpenMaxRequests(2) would already
+	// be exhausted by the one phantom in-flight call, rejecting this pair.
+	_, err := circuitbreaker.Execute(context.Background(), cb, func(ctx context.Context) (int, error) {
+		_, err := circuitbreaker.Execute(context.Background(), cb, func(ctx context.Context) (int, error) {
+			return 0, nil
+		})
+		assert(t, err, nil)
+		return 0, nil
+	})
+	assert(t, err, nil)
+}
+
+func TestExecuteCallTimeout(t *testing.T) {
+	cb := circuitbreaker.
+		New().
+		SetCallTimeout(10 * time.Millisecond).
+		Build()
+
+	_, err := circuitbreaker.Execute(context.Background(), cb, func(ctx context.Context) (int, error) {
+		<-ctx.Done()
+		return 0, ctx.Err()
+	})
+	assert(t, err, context.DeadlineExceeded)
+
+	status := cb.Inspect()
+	assert(t, status.ActiveNode.FailureCount, 1)
+	assert(t, status.ActiveNode.TimeoutCount, 1)
+}
+
+func TestLazyRotation(t *testing.T) {
+	mockTime := mocktime.NewMockTime(time.Now())
+
+	cb := circuitbreaker.
+		New().
+		UNSAFESetTime(mockTime).
+		SetEvalWindow(1, 1).
+		SetErrorThreshold(10.0).
+		SetLazyRotation(true).
+		Build()
+
+	RecordSuccesses(200, cb)
+	mockTime.MockCurrentTime = mockTime.MockCurrentTime.Add(61 * time.Second)
+	RecordErrors(250, cb)
+	mockTime.MockCurrentTime = mockTime.MockCurrentTime.Add(61 * time.Second)
+	RecordErrors(1, cb)
+
+	assert(t, circuitbreaker.Open, cb.GetState())
+}
+
+func TestExecuteWithFallback(t *testing.T) {
+	mockTime := mocktime.NewMockTime(time.Now())
+
+	cb := circuitbreaker.
+		New().
+		UNSAFESetTime(mockTime).
+		SetEvalWindow(1, 1).
+		SetErrorThreshold(10.0).
+		Build()
+
+	RecordSuccesses(200, cb)
+	mockTime.FastForward(61 * time.Second)
+	RecordErrors(250, cb)
+	mockTime.FastForward(61 * time.Second)
+	RecordErrors(1, cb)
+	assert(t, circuitbreaker.Open, cb.GetState())
+
+	got, err := circuitbreaker.ExecuteWithFallback(context.Background(), cb, func(ctx context.Context) (int, error) {
+		t.Fatal("fn should not be called while the circuit is Open")
+		return 0, nil
+	}, func(err error) (int, error) {
+		return -1, nil
+	})
+	assert(t, err, nil)
+	assert(t, got, -1)
+}
+
+func TestExecuteWithFallbackRecoversPanic(t *testing.T) {
+	cb := circuitbreaker.New().Build()
+
+	defer func() {
+		r := recover()
+		if r == nil {
+			t.Fatal("expected ExecuteWithFallback to re-panic")
+		}
+
+		status := cb.Inspect()
+		assert(t, status.ActiveNode.FailureCount, 1)
+	}()
+
+	_, _ = circuitbreaker.ExecuteWithFallback(context.Background(), cb, func(ctx context.Context) (int, error) {
+		panic("boom")
+	}, nil)
+}
+
+// TestExecutePanicReleasesHalfOpenSlot exercises bare Execute (not
+// ExecuteWithFallback) to confirm panic recovery, Failure recording, and
+// releasing the HalfOpen admission slot all happen regardless of which
+// wrapper the caller chose.
+func TestExecutePanicReleasesHalfOpenSlot(t *testing.T) {
+	mockTime := mocktime.NewMockTime(time.Now())
+
+	cb := circuitbreaker.
+		New().
+		UNSAFESetTime(mockTime).
+		SetEvalWindow(1, 1).
+		SetErrorThreshold(10.0).
+		SetRetryTimeout(time.Minute).
+		SetHalfOpenMaxRequests(1).
+		Build()
+
+	RecordSuccesses(200, cb)
+	mockTime.FastForward(61 * time.Second)
+	RecordErrors(250, cb)
+	mockTime.FastForward(61 * time.Second)
+	RecordErrors(1, cb)
+	mockTime.FastForward(61 * time.Second)
+
+	assert(t, circuitbreaker.HalfOpen, cb.GetState())
+
+	func() {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Fatal("expected Execute to re-panic")
+			}
+		}()
+
+		_, _ = circuitbreaker.Execute(context.Background(), cb, func(ctx context.Context) (int, error) {
+			panic("boom")
+		})
+	}()
+
+	assert(t, circuitbreaker.Open, cb.GetState())
+
+	mockTime.FastForward(61 * time.Second)
+	assert(t, circuitbreaker.HalfOpen, cb.GetState())
+
+	// If the panic had leaked the admission slot, this would be rejected with
+	// ErrTooManyRequests even though the panicking call already finished.
+	_, err := circuitbreaker.Execute(context.Background(), cb, func(ctx context.Context) (int, error) {
+		return 0, nil
+	})
+	assert(t, err, nil)
+}
+
+type recordingBackoff struct {
+	attempts []int
+}
+
+func (r *recordingBackoff) Next(attempt int) time.Duration {
+	r.attempts = append(r.attempts, attempt)
+	return time.Duration(attempt+1) * time.Minute
+}
+
+func (r *recordingBackoff) Reset() {
+	r.attempts = append(r.attempts, -1)
+}
+
+func TestBackoffAttemptIncrements(t *testing.T) {
+	mockTime := mocktime.NewMockTime(time.Now())
+	backoff := &recordingBackoff{}
+
+	cb := circuitbreaker.
+		New().
+		UNSAFESetTime(mockTime).
+		SetEvalWindow(1, 1).
+		SetErrorThreshold(10.0).
+		SetBackoff(backoff).
+		Build()
+
+	RecordSuccesses(200, cb)
+	mockTime.FastForward(61 * time.Second)
+	RecordErrors(250, cb)
+	mockTime.FastForward(61 * time.Second)
+	RecordErrors(1, cb)
+	assert(t, circuitbreaker.Open, cb.GetState())
+
+	mockTime.FastForward(61 * time.Second)
+	assert(t, circuitbreaker.HalfOpen, cb.GetState())
+
+	RecordErrors(1, cb)
+	assert(t, circuitbreaker.Open, cb.GetState())
+
+	assert(t, backoff.attempts[0], 0)
+	assert(t, backoff.attempts[1], 1)
+}
+
+func TestHealthProbePromotesToHalfOpen(t *testing.T) {
+	mockTime := mocktime.NewMockTime(time.Now())
+	probeErr := errors.New("still down")
+
+	cb := circuitbreaker.
+		New().
+		UNSAFESetTime(mockTime).
+		SetEvalWindow(1, 1).
+		SetErrorThreshold(10.0).
+		SetHealthProbe(time.Second, func(ctx context.Context) error {
+			return probeErr
+		}).
+		Build()
+
+	RecordSuccesses(200, cb)
+	mockTime.FastForward(61 * time.Second)
+	RecordErrors(250, cb)
+	mockTime.FastForward(61 * time.Second)
+	RecordErrors(1, cb)
+	assert(t, circuitbreaker.Open, cb.GetState())
+
+	mockTime.FastForward(1100 * time.Millisecond)
+	assert(t, circuitbreaker.Open, cb.GetState())
+
+	failures, _ := cb.UNSAFEGetProbeState()
+	assert(t, failures > 0, true)
+
+	probeErr = nil
+	mockTime.FastForward(1100 * time.Millisecond)
+	assert(t, circuitbreaker.HalfOpen, cb.GetState())
+}
+
 // Record() is the most frequently used method in the Circuit Breaker
 func BenchmarkCircuitBreakerRecord(b *testing.B) {
 	cb := circuitbreaker.New().Build()
@@ -212,6 +522,33 @@ func BenchmarkCircuitBreakerRecord(b *testing.B) {
 	}
 }
 
+func TestSetOnStateChangeReason(t *testing.T) {
+	mockTime := mocktime.NewMockTime(time.Now())
+
+	var gotFrom, gotTo circuitbreaker.State
+	var gotReason circuitbreaker.StateChangeReason
+
+	cb := circuitbreaker.
+		New().
+		UNSAFESetTime(mockTime).
+		SetEvalWindow(1, 1).
+		SetErrorThreshold(10.0).
+		SetOnStateChange(func(from, to circuitbreaker.State, at time.Time, reason circuitbreaker.StateChangeReason) {
+			gotFrom, gotTo, gotReason = from, to, reason
+		}).
+		Build()
+
+	RecordSuccesses(200, cb)
+	mockTime.FastForward(61 * time.Second)
+	RecordErrors(250, cb)
+	mockTime.FastForward(61 * time.Second)
+	RecordErrors(1, cb)
+
+	assert(t, circuitbreaker.Closed, gotFrom)
+	assert(t, circuitbreaker.Open, gotTo)
+	assert(t, circuitbreaker.ThresholdExceeded, gotReason)
+}
+
 // GetState() may be frequently used by consumers to determine if they will defer sending traffic to a unavailable service
 func BenchmarkCircuitGetState(b *testing.B) {
 	cb := circuitbreaker.New().Build()
@@ -2,6 +2,7 @@ package app
 
 import (
 	"fmt"
+	"net/http"
 	"os"
 	"time"
 
@@ -18,26 +19,47 @@ type model struct {
 	circuitBreaker *circuitbreaker.CircuitBreaker
 	es             *EventDisplayState
 	ui             *UI
+	// targetURL and targetClient are set when --target-url is configured, in
+	// which case the model polls a real dependency instead of waiting on
+	// 'f'/'s' keypresses.
+	targetURL    string
+	targetClient *http.Client
 }
 
 func initialModel(config *ProgramConfig) model {
+	retryTimeout := time.Duration(config.RetryTimeout) * time.Second
+
+	backoff, err := parseBackoff(config.RetryBackoff, retryTimeout)
+	if err != nil {
+		fmt.Printf("Invalid --retry-backoff: %v", err)
+		os.Exit(1)
+	}
+
 	cb := circuitbreaker.New().
 		SetEvalWindow(config.WindowDuration, config.Spans-1).
 		SetMinEvalSize(config.MinEvalSize).
 		SetErrorThreshold(config.ErrorThreshold).
-		SetRetryTimeout(time.Duration(config.RetryTimeout) * time.Second).
+		SetRetryTimeout(retryTimeout).
 		SetTrialSuccessesRequired(config.TrialSuccessesRequired).
+		SetBackoff(backoff).
 		Build()
 
 	es := NewEventDisplayState(300 * time.Millisecond)
 
 	ui := NewUI(cb, es)
 
-	return model{
+	m := model{
 		circuitBreaker: cb,
 		es:             es,
 		ui:             ui,
 	}
+
+	if config.TargetURL != "" {
+		m.targetURL = config.TargetURL
+		m.targetClient = newTargetClient(cb)
+	}
+
+	return m
 }
 
 func tickEvery(duration time.Duration) tea.Cmd {
@@ -47,6 +69,9 @@ func tickEvery(duration time.Duration) tea.Cmd {
 }
 
 func (m model) Init() tea.Cmd {
+	if m.targetURL != "" {
+		return tea.Batch(tickEvery(refreshInterval), pingTick())
+	}
 	return tickEvery(refreshInterval)
 }
 
@@ -65,6 +90,15 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 	case time.Time:
 		return m, tickEvery(refreshInterval)
+	case pingTickMsg:
+		return m, pingTarget(m.targetClient, m.targetURL)
+	case pingResultMsg:
+		if msg.result == circuitbreaker.Success {
+			m.es.RecordEvent(Success)
+		} else {
+			m.es.RecordEvent(Fail)
+		}
+		return m, pingTick()
 	}
 	return m, nil
 }
@@ -0,0 +1,46 @@
+package app
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"circuitbreaker"
+)
+
+// parseBackoff parses the --retry-backoff flag value into a circuitbreaker.Backoff.
+//
+// Supported specs:
+//   - "constant" (or empty): ConstantBackoff(retryTimeout)
+//   - "exponential:<base>:<max>:<factor>", e.g. "exponential:1s:60s:2.0"
+func parseBackoff(spec string, retryTimeout time.Duration) (circuitbreaker.Backoff, error) {
+	if spec == "" || spec == "constant" {
+		return circuitbreaker.ConstantBackoff(retryTimeout), nil
+	}
+
+	parts := strings.Split(spec, ":")
+	if parts[0] != "exponential" {
+		return nil, fmt.Errorf("unknown backoff strategy %q", parts[0])
+	}
+	if len(parts) != 4 {
+		return nil, fmt.Errorf("exponential backoff spec must be exponential:<base>:<max>:<factor>, got %q", spec)
+	}
+
+	base, err := time.ParseDuration(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid exponential backoff base %q: %w", parts[1], err)
+	}
+
+	max, err := time.ParseDuration(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("invalid exponential backoff max %q: %w", parts[2], err)
+	}
+
+	factor, err := strconv.ParseFloat(parts[3], 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid exponential backoff factor %q: %w", parts[3], err)
+	}
+
+	return circuitbreaker.ExponentialBackoff{Base: base, Max: max, Factor: factor}, nil
+}
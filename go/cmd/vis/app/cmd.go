@@ -9,6 +9,8 @@ type ProgramConfig struct {
 	ErrorThreshold         float64
 	RetryTimeout           int
 	TrialSuccessesRequired int
+	RetryBackoff           string
+	TargetURL              string
 }
 
 func Cmd() *cobra.Command {
@@ -19,6 +21,7 @@ func Cmd() *cobra.Command {
 		ErrorThreshold:         10.0,
 		RetryTimeout:           100,
 		TrialSuccessesRequired: 20,
+		RetryBackoff:           "constant",
 	}
 
 	cmd := &cobra.Command{
@@ -36,6 +39,8 @@ func Cmd() *cobra.Command {
 	cmd.Flags().Float64VarP(&config.ErrorThreshold, "error-threshold", "e", 10.0, "The error rate threshold that will cause the circuit breaker to open. Defaults to 10.0.")
 	cmd.Flags().IntVarP(&config.RetryTimeout, "retry", "r", 100, "The duration in seconds the circuit breaker remains in the Open state before transitioning to Half-Open. Defaults to 60.")
 	cmd.Flags().IntVarP(&config.TrialSuccessesRequired, "trials", "t", 20, "The number of consecutive successful requests needed while in the Half-Open state before the circuit breaker transitions back to the Closed state. Defaults to 20.")
+	cmd.Flags().StringVar(&config.RetryBackoff, "retry-backoff", "constant", "The backoff strategy used to compute the Open->HalfOpen delay: \"constant\", or \"exponential:<base>:<max>:<factor>\" (e.g. exponential:1s:60s:2.0). Defaults to constant, using --retry.")
+	cmd.Flags().StringVar(&config.TargetURL, "target-url", "", "If set, poll this URL through the circuit breaker's RoundTripper instead of driving it with 'f'/'s' keypresses, so the TUI visualises a real dependency.")
 
 	return cmd
 }
@@ -0,0 +1,51 @@
+package app
+
+import (
+	"net/http"
+	"time"
+
+	"circuitbreaker"
+	cbhttp "circuitbreaker/http"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// pingInterval is how often the visualiser polls --target-url. It is
+// independent of refreshInterval, which only redraws the UI.
+const pingInterval = 500 * time.Millisecond
+
+// pingResultMsg reports the outcome of a single request to --target-url,
+// already classified the same way the RoundTripper classified it for cb, so
+// the UI's event flash agrees with what was actually recorded.
+type pingResultMsg struct {
+	result circuitbreaker.Result
+}
+
+// pingTickMsg is its own type, distinct from the plain time.Time used by
+// tickEvery, so Update can tell the two tickers apart.
+type pingTickMsg time.Time
+
+// newTargetClient wraps http.DefaultTransport with the circuit breaker's
+// RoundTripper, so polling a real dependency drives cb the same way Record
+// does for the manual 'f'/'s' keypresses.
+func newTargetClient(cb *circuitbreaker.CircuitBreaker) *http.Client {
+	return &http.Client{
+		Transport: cbhttp.NewRoundTripper(cb, http.DefaultTransport, nil),
+	}
+}
+
+func pingTick() tea.Cmd {
+	return tea.Tick(pingInterval, func(t time.Time) tea.Msg {
+		return pingTickMsg(t)
+	})
+}
+
+func pingTarget(client *http.Client, url string) tea.Cmd {
+	return func() tea.Msg {
+		resp, err := client.Get(url)
+		if resp != nil {
+			resp.Body.Close()
+		}
+		return pingResultMsg{result: cbhttp.DefaultClassify(resp, err)}
+	}
+}
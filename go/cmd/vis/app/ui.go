@@ -74,9 +74,8 @@ type BufferLayout struct {
 type MiddleBuffer = []int
 
 type UI struct {
-	cb         *circuitbreaker.CircuitBreaker
-	es         *EventDisplayState
-	retryStart time.Time
+	cb *circuitbreaker.CircuitBreaker
+	es *EventDisplayState
 }
 
 func NewUI(cb *circuitbreaker.CircuitBreaker, es *EventDisplayState) *UI {
@@ -373,21 +372,11 @@ func (ui *UI) stateToString() string {
 	}
 }
 
+// getRetryTime reads the retry deadline currently scheduled by the circuit
+// breaker's configured Backoff, rather than caching a static duration, so it
+// reflects each trip's actual (possibly backed-off) wait time.
 func (ui *UI) getRetryTime() float64 {
-	// Initialize retryStart if not set
-	if ui.retryStart.IsZero() {
-		ui.retryStart = time.Now().Add(ui.cb.UNSAFEGetRetryTimeout())
-	}
-
-	// Calculate remaining time
-	remaining := math.Max(0, time.Until(ui.retryStart).Seconds())
-
-	// Reset retryStart if time has elapsed
-	if remaining <= 0 {
-		ui.retryStart = time.Time{}
-	}
-
-	return remaining
+	return math.Max(0, time.Until(ui.cb.UNSAFEGetRetryDeadline()).Seconds())
 }
 
 // The state indicator dynamically shows the most relevant information give the circuit state:
@@ -405,6 +394,7 @@ func (ui *UI) stateIndicator() string {
 	switch state {
 	case circuitbreaker.Open:
 		str = fmt.Sprintf("                          Retry: %.1fs", ui.getRetryTime())
+		str += "\n" + ui.probeIndicator()
 	case circuitbreaker.Closed:
 		str = fmt.Sprintf("                    Next Buffer: %.1fs", cursorExpiresIn)
 	case circuitbreaker.HalfOpen:
@@ -414,6 +404,18 @@ func (ui *UI) stateIndicator() string {
 	return str
 }
 
+// probeIndicator shows the active health probe's recent failures, if a
+// probe is configured via SetHealthProbe. It is blank when no probe is
+// configured, since UNSAFEGetProbeState's zero value is indistinguishable
+// from "never failed".
+func (ui *UI) probeIndicator() string {
+	failures, lastFailureAt := ui.cb.UNSAFEGetProbeState()
+	if failures == 0 {
+		return ""
+	}
+	return fmt.Sprintf("                    Probe: %d failures, last %s ago", failures, time.Since(lastFailureAt).Round(time.Second))
+}
+
 func (ui *UI) Render() string {
 	// cursor := ui.cb.UNSAFEGetActiveCursor()
 
@@ -0,0 +1,113 @@
+package circuitbreaker
+
+import (
+	"sync"
+	"time"
+)
+
+// subscriberBuffer is the number of StateEvents buffered per Subscribe channel
+// before new events are dropped for that subscriber. A slow consumer should
+// not be able to block a circuit breaker transition.
+const subscriberBuffer = 16
+
+// StateEvent describes a single state transition (or other notable lifecycle
+// event, such as a trial success or a buffer rotation) published by a
+// CircuitBreaker to its subscribers.
+type StateEvent struct {
+	Name   string
+	From   State
+	To     State
+	Status *Status
+}
+
+type dispatcher struct {
+	mu                 sync.Mutex
+	onChange           []func(name string, from, to State, status *Status)
+	onChangeWithReason []func(from, to State, at time.Time, reason StateChangeReason)
+	subscribers        map[chan StateEvent]struct{}
+}
+
+func newDispatcher() *dispatcher {
+	return &dispatcher{
+		subscribers: make(map[chan StateEvent]struct{}),
+	}
+}
+
+// publish notifies every registered callback and channel subscriber. It never
+// blocks: callbacks are expected to be cheap, and channel sends are
+// best-effort, dropping the event for any subscriber whose buffer is full.
+func (d *dispatcher) publish(event StateEvent) {
+	d.mu.Lock()
+	callbacks := d.onChange
+	subs := make([]chan StateEvent, 0, len(d.subscribers))
+	for ch := range d.subscribers {
+		subs = append(subs, ch)
+	}
+	d.mu.Unlock()
+
+	for _, cb := range callbacks {
+		cb(event.Name, event.From, event.To, event.Status)
+	}
+
+	for _, ch := range subs {
+		select {
+		case ch <- event:
+		default:
+			// subscriber's buffer is full, drop the event rather than block
+		}
+	}
+}
+
+func (d *dispatcher) subscribe() (<-chan StateEvent, func()) {
+	ch := make(chan StateEvent, subscriberBuffer)
+
+	d.mu.Lock()
+	d.subscribers[ch] = struct{}{}
+	d.mu.Unlock()
+
+	cancel := func() {
+		d.mu.Lock()
+		if _, ok := d.subscribers[ch]; ok {
+			delete(d.subscribers, ch)
+			close(ch)
+		}
+		d.mu.Unlock()
+	}
+
+	return ch, cancel
+}
+
+// OnStateChange registers a callback invoked whenever the circuit breaker
+// transitions between Closed, Open, and HalfOpen (and on other notable
+// lifecycle events, such as a trial success being recorded). The callback
+// must not block and must not call back into the breaker it was registered
+// on, as it runs while internal state is being published.
+//
+// Multiple calls to OnStateChange register additional callbacks; none of them
+// replace a previously registered one.
+func (b *Builder) OnStateChange(fn func(name string, from, to State, status *Status)) *Builder {
+	b.cb.events.onChange = append(b.cb.events.onChange, fn)
+	return b
+}
+
+// Subscribe returns a channel of StateEvents for this circuit breaker, along
+// with a cancel function that must be called once the subscriber is done to
+// release its resources.
+//
+// The channel is buffered; a subscriber that falls behind will miss events
+// rather than block the circuit breaker.
+func (cb *CircuitBreaker) Subscribe() (<-chan StateEvent, func()) {
+	return cb.events.subscribe()
+}
+
+// publishStateChange publishes a StateEvent for the given transition. The
+// caller must already hold cb.mu, as is the case at every call site in
+// moveCursor, Record, and the retry scheduler callback.
+func (cb *CircuitBreaker) publishStateChange(name string, from, to State) {
+	cb.events.publish(StateEvent{
+		Name:   name,
+		From:   from,
+		To:     to,
+		Status: cb.snapshotLocked(),
+	})
+}
@@ -0,0 +1,160 @@
+package circuitbreaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrCircuitOpen is returned by Execute and Do when the circuit breaker is in
+// the Open state and the call is short-circuited without invoking fn.
+var ErrCircuitOpen = errors.New("circuitbreaker: circuit is open")
+
+// Execute runs fn through the circuit breaker, translating its result into a
+// Success or Failure via the breaker's configured IsSuccessful classifier and
+// recording it via the same path as Record.
+//
+// When the circuit is Open, fn is not invoked and Execute returns the zero
+// value of T along with ErrCircuitOpen. When the circuit is HalfOpen, fn may
+// also be rejected with ErrTooManyRequests if it doesn't pass the configured
+// HalfOpen admission control (SetHalfOpenMaxRequests/SetHalfOpenAdmitProbability);
+// whatever admission slot was reserved for an admitted call is always
+// released when the call completes, however it completes - including fn
+// panicking - so a HalfOpen admission slot can never leak.
+//
+// A panic inside fn is recovered, recorded as a Failure, and re-raised, so
+// callers get the same crash they would have without the circuit breaker
+// wrapping fn, but the breaker's accounting (and admission slot release)
+// still reflects the failure.
+//
+// Execute is a package-level function rather than a method because Go does
+// not allow methods to introduce their own type parameters; pair it with
+// *CircuitBreaker the same way you would call cb.Record.
+func Execute[T any](ctx context.Context, cb *CircuitBreaker, fn func(context.Context) (T, error)) (result T, err error) {
+	cb.mu.Lock()
+	release, admitErr := cb.admitLocked()
+	cb.mu.Unlock()
+
+	if admitErr != nil {
+		err = admitErr
+		return
+	}
+	defer release()
+
+	defer func() {
+		if r := recover(); r != nil {
+			cb.record(Failure, false)
+			panic(r)
+		}
+	}()
+
+	callCtx := ctx
+	var cancel context.CancelFunc
+	if timeout := cb.callTimeout(); timeout > 0 {
+		callCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	result, err = fn(callCtx)
+
+	if err != nil && callCtx.Err() == context.DeadlineExceeded && ctx.Err() != context.DeadlineExceeded {
+		cb.record(Failure, true)
+		return result, err
+	}
+
+	if cb.isSuccessful(err) {
+		cb.Record(Success)
+	} else {
+		cb.Record(Failure)
+	}
+
+	return result, err
+}
+
+// ExecuteWithFallback wraps Execute with a fallback invoked whenever the
+// call is short-circuited (ErrCircuitOpen/ErrTooManyRequests) or fn itself
+// panics. Recording the outcome and releasing any HalfOpen admission slot is
+// handled entirely by Execute, including on panic; ExecuteWithFallback only
+// adds the fallback behavior on top.
+//
+// Success/Failure classification (including treating specific errors such as
+// context.Canceled as non-failures) is configured the same way as Execute,
+// via SetIsSuccessful - there is no separate classifier here.
+//
+// If fallback is nil, ExecuteWithFallback behaves exactly like Execute and
+// re-panics immediately on a recovered panic.
+func ExecuteWithFallback[T any](ctx context.Context, cb *CircuitBreaker, fn func(context.Context) (T, error), fallback func(error) (T, error)) (result T, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if fallback != nil {
+				// fallback's return value is discarded here: Execute has
+				// already recorded the panic as a Failure and released any
+				// HalfOpen admission slot, and the panic always re-propagates
+				// to the caller unchanged regardless of what fallback
+				// returns. fallback only gets a chance to run for its side
+				// effects (e.g. logging) before that happens.
+				fallback(panicError{r})
+			}
+			panic(r)
+		}
+	}()
+
+	result, err = Execute(ctx, cb, fn)
+
+	if fallback != nil && (errors.Is(err, ErrCircuitOpen) || errors.Is(err, ErrTooManyRequests)) {
+		return fallback(err)
+	}
+
+	return result, err
+}
+
+// panicError adapts an arbitrary recover() value into an error so fallback
+// functions (which take an error) can inspect what fn panicked with.
+type panicError struct{ value any }
+
+func (p panicError) Error() string {
+	return fmt.Sprintf("circuitbreaker: recovered panic: %v", p.value)
+}
+
+func (cb *CircuitBreaker) callTimeout() time.Duration {
+	cb.mu.RLock()
+	defer cb.mu.RUnlock()
+	return cb.config.CallTimeout
+}
+
+// Do runs fn through the circuit breaker, following the same short-circuit
+// and classification rules as Execute. It is a convenience for callers that
+// have no value to return, only an error.
+func (cb *CircuitBreaker) Do(ctx context.Context, fn func(context.Context) error) error {
+	_, err := Execute(ctx, cb, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, fn(ctx)
+	})
+	return err
+}
+
+func (cb *CircuitBreaker) isSuccessful(err error) bool {
+	cb.mu.RLock()
+	isSuccessful := cb.config.IsSuccessful
+	cb.mu.RUnlock()
+
+	if isSuccessful == nil {
+		return err == nil
+	}
+
+	return isSuccessful(err)
+}
+
+// SetIsSuccessful configures how errors returned by Execute/Do are classified
+// into Success/Failure results.
+//
+// This allows callers to treat errors such as context.DeadlineExceeded or
+// specific gRPC/HTTP status codes as successes (or vice versa) rather than
+// relying on the default "err == nil" classification.
+//
+// If not set, the default classifier treats a nil error as Success and any
+// non-nil error as Failure.
+func (b *Builder) SetIsSuccessful(fn func(error) bool) *Builder {
+	b.cb.config.IsSuccessful = fn
+	return b
+}
@@ -0,0 +1,70 @@
+package circuitbreaker
+
+import (
+	"context"
+	"time"
+
+	"circuitbreaker/internal/scheduler"
+)
+
+// HealthProbe actively checks whether a downstream dependency has recovered
+// while the circuit is Open, instead of blindly waiting for a fixed or
+// backed-off timeout to elapse before letting real traffic try again.
+type HealthProbe struct {
+	Interval time.Duration
+	Fn       func(context.Context) error
+}
+
+// SetHealthProbe configures an active health probe, following Tailscale's
+// prober: while the circuit is Open, Fn is called every interval instead of
+// the circuit auto-promoting to HalfOpen on a timer. A single successful
+// probe call transitions the circuit to HalfOpen; a failing probe extends
+// the Open window (using the configured Backoff, see SetBackoff, for display
+// via UNSAFEGetRetryDeadline) and keeps probing.
+//
+// If not set, the circuit breaker promotes Open->HalfOpen purely on a timer,
+// per SetBackoff/SetRetryTimeout.
+func (b *Builder) SetHealthProbe(interval time.Duration, fn func(context.Context) error) *Builder {
+	b.cb.healthProbe = &HealthProbe{Interval: interval, Fn: fn}
+	return b
+}
+
+// startHealthProbeLocked starts polling the configured HealthProbe. The
+// caller must already hold cb.mu, and must have already set cb.state to Open.
+func (cb *CircuitBreaker) startHealthProbeLocked() {
+	probe := cb.healthProbe
+
+	cb.probeScheduler = scheduler.New(cb.time, probe.Interval, func() {
+		err := probe.Fn(context.Background())
+
+		cb.mu.Lock()
+		defer cb.mu.Unlock()
+
+		if cb.state != Open {
+			return
+		}
+
+		if err == nil {
+			from := cb.state
+			cb.state = HalfOpen
+			cb.probeScheduler.Stop()
+			cb.publishStateChangeReason(from, HalfOpen, HealthProbeRecovered)
+			return
+		}
+
+		cb.probeFailures++
+		cb.probeLastFailureAt = cb.time.Now()
+		cb.retryDeadline = cb.probeLastFailureAt.Add(cb.backoffLocked().Next(cb.probeFailures))
+	})
+	cb.probeScheduler.Start()
+}
+
+// UNSAFEGetProbeState reports the number of consecutive failed health probe
+// calls since the circuit last opened, and when the most recent one failed.
+//
+// UNSAFE - only intended for use by internal tooling such as the vis UI.
+func (cb *CircuitBreaker) UNSAFEGetProbeState() (failures int, lastFailureAt time.Time) {
+	cb.mu.RLock()
+	defer cb.mu.RUnlock()
+	return cb.probeFailures, cb.probeLastFailureAt
+}
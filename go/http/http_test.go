@@ -0,0 +1,204 @@
+package http_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"circuitbreaker"
+	cbhttp "circuitbreaker/http"
+	"circuitbreaker/internal/mocktime"
+)
+
+type fakeRoundTripper struct {
+	resp *http.Response
+}
+
+func (f *fakeRoundTripper) RoundTrip(*http.Request) (*http.Response, error) {
+	return f.resp, nil
+}
+
+func TestRoundTripperRecordsAndShortCircuitsWhenOpen(t *testing.T) {
+	ft := mocktime.NewMockTime(time.Now())
+	cb := circuitbreaker.
+		New().
+		UNSAFESetTime(ft).
+		SetEvalWindow(1, 60).
+		SetLazyRotation(true).
+		SetTripPolicy(circuitbreaker.ConsecutiveFailuresPolicy(1)).
+		Build()
+
+	inner := &fakeRoundTripper{resp: &http.Response{StatusCode: http.StatusInternalServerError, Body: http.NoBody}}
+	rt := cbhttp.NewRoundTripper(cb, inner, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "http://example.com", nil)
+
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("expected the inner response to pass through, got %d", resp.StatusCode)
+	}
+
+	ft.FastForward(2 * time.Second)
+
+	if _, err := rt.RoundTrip(req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cb.GetState() != circuitbreaker.Open {
+		t.Fatalf("expected the circuit to be Open after consecutive failures, got %v", cb.GetState())
+	}
+
+	resp, err = rt.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected a synthetic 503 while Open, got %d", resp.StatusCode)
+	}
+	if resp.Header.Get("Retry-After") == "" {
+		t.Fatal("expected a Retry-After header on the synthetic 503")
+	}
+}
+
+// TestRoundTripperRespectsHalfOpenMaxRequests confirms RoundTrip is run
+// through circuitbreaker.Execute, so HalfOpen admission control actually
+// bounds concurrent trial requests instead of being a no-op for HTTP calls.
+func TestRoundTripperRespectsHalfOpenMaxRequests(t *testing.T) {
+	ft := mocktime.NewMockTime(time.Now())
+	cb := circuitbreaker.
+		New().
+		UNSAFESetTime(ft).
+		SetEvalWindow(1, 60).
+		SetLazyRotation(true).
+		SetTripPolicy(circuitbreaker.ConsecutiveFailuresPolicy(1)).
+		SetRetryTimeout(time.Minute).
+		SetHalfOpenMaxRequests(1).
+		Build()
+
+	inner := &fakeRoundTripper{resp: &http.Response{StatusCode: http.StatusInternalServerError, Body: http.NoBody}}
+	rt := cbhttp.NewRoundTripper(cb, inner, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "http://example.com", nil)
+
+	if _, err := rt.RoundTrip(req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ft.FastForward(2 * time.Second)
+	if _, err := rt.RoundTrip(req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cb.GetState() != circuitbreaker.Open {
+		t.Fatalf("expected the circuit to be Open after consecutive failures, got %v", cb.GetState())
+	}
+
+	ft.FastForward(61 * time.Second)
+	if cb.GetState() != circuitbreaker.HalfOpen {
+		t.Fatalf("expected the circuit to be HalfOpen after the retry timeout, got %v", cb.GetState())
+	}
+
+	blocking := &blockingRoundTripper{release: make(chan struct{})}
+	rt = cbhttp.NewRoundTripper(cb, blocking, nil)
+
+	done := make(chan struct{})
+	go func() {
+		rt.RoundTrip(req)
+		close(done)
+	}()
+	<-blocking.started
+
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected the second concurrent HalfOpen trial to be rejected with a synthetic 503, got %d", resp.StatusCode)
+	}
+
+	close(blocking.release)
+	<-done
+}
+
+type blockingRoundTripper struct {
+	started chan struct{}
+	release chan struct{}
+	once    sync.Once
+}
+
+func (b *blockingRoundTripper) RoundTrip(*http.Request) (*http.Response, error) {
+	b.once.Do(func() { close(b.started) })
+	<-b.release
+	return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+}
+
+func TestMiddlewareRefusesWhileOpen(t *testing.T) {
+	ft := mocktime.NewMockTime(time.Now())
+	cb := circuitbreaker.
+		New().
+		UNSAFESetTime(ft).
+		SetEvalWindow(1, 60).
+		SetLazyRotation(true).
+		SetTripPolicy(circuitbreaker.ConsecutiveFailuresPolicy(1)).
+		Build()
+
+	handler := cbhttp.Middleware(cb, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "http://example.com", nil)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected the handler's response to pass through while Closed, got %d", w.Code)
+	}
+
+	ft.FastForward(2 * time.Second)
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if cb.GetState() != circuitbreaker.Open {
+		t.Fatalf("expected the circuit to be Open after consecutive failures, got %v", cb.GetState())
+	}
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 once the circuit is Open, got %d", w.Code)
+	}
+	if w.Header().Get("Retry-After") == "" {
+		t.Fatal("expected a Retry-After header on the 503")
+	}
+}
+
+// TestMiddlewareRecordsPanicAsFailure confirms a panicking handler is
+// recorded as a Failure (via Execute's own recover) rather than silently
+// escaping cb's accounting, since net/http's per-connection recovery would
+// otherwise hide the failure from the breaker entirely.
+func TestMiddlewareRecordsPanicAsFailure(t *testing.T) {
+	cb := circuitbreaker.New().SetTripPolicy(circuitbreaker.ConsecutiveFailuresPolicy(100)).Build()
+
+	handler := cbhttp.Middleware(cb, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "http://example.com", nil)
+
+	func() {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Fatal("expected the panic to propagate out of ServeHTTP")
+			}
+		}()
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}()
+
+	failures, _ := cb.CountRecent(time.Minute)
+	if failures != 1 {
+		t.Fatalf("expected the panic to be recorded as a failure, got %d recent failures", failures)
+	}
+}
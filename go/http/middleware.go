@@ -0,0 +1,78 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"circuitbreaker"
+)
+
+// StatusClassify decides whether a completed inbound request counts as a
+// circuitbreaker.Success or circuitbreaker.Failure, given the status code
+// the handler wrote. It is the server-side counterpart to Classify.
+type StatusClassify func(status int) circuitbreaker.Result
+
+// DefaultStatusClassify treats a 5xx status as Failure and everything else
+// (including 4xx) as Success, mirroring DefaultClassify.
+func DefaultStatusClassify(status int) circuitbreaker.Result {
+	if status >= 500 {
+		return circuitbreaker.Failure
+	}
+	return circuitbreaker.Success
+}
+
+// Middleware wraps an inbound http.Handler with cb: while cb is Open, or
+// once HalfOpen admission rejects the request, requests are refused with a
+// 503 and a Retry-After header derived from cb's current retry/backoff
+// countdown instead of reaching next. An admitted request runs through
+// circuitbreaker.Execute, so SetHalfOpenMaxRequests,
+// SetHalfOpenAdmitProbability, and SetCallTimeout all apply the same way
+// they would to any other Execute call, and a panic inside next is recovered
+// by Execute, recorded as a Failure, and re-raised - net/http's per-connection
+// recovery no longer silently hides that failure from cb.
+//
+// classify decides whether the status code next wrote counts as a Success or
+// Failure; if nil, DefaultStatusClassify is used.
+func Middleware(cb *circuitbreaker.CircuitBreaker, classify StatusClassify) func(http.Handler) http.Handler {
+	if classify == nil {
+		classify = DefaultStatusClassify
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, err := circuitbreaker.Execute(r.Context(), cb, func(ctx context.Context) (struct{}, error) {
+				sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+				next.ServeHTTP(sw, r.WithContext(ctx))
+
+				if classify(sw.status) == circuitbreaker.Failure {
+					return struct{}{}, errStatusClassifiedFailure
+				}
+				return struct{}{}, nil
+			})
+
+			if errors.Is(err, circuitbreaker.ErrCircuitOpen) || errors.Is(err, circuitbreaker.ErrTooManyRequests) {
+				w.Header().Set("Retry-After", retryAfterSeconds(cb))
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+		})
+	}
+}
+
+// errStatusClassifiedFailure adapts a StatusClassify Failure verdict into the
+// error Execute's IsSuccessful classifier sees. It never reaches the caller:
+// the handler has already written its own status and body via w by the time
+// Execute sees it.
+var errStatusClassifiedFailure = errors.New("circuitbreaker/http: classified as failure")
+
+// statusWriter captures the status code written by the wrapped handler so
+// Middleware can classify the outcome after ServeHTTP returns.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
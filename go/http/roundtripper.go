@@ -0,0 +1,137 @@
+// Package http provides net/http integrations for CircuitBreaker: a
+// RoundTripper that wraps an outbound client and a Middleware that wraps an
+// inbound http.Handler, so a breaker can be dropped in around a real
+// dependency instead of wired by hand around individual calls.
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"circuitbreaker"
+)
+
+// Classify decides whether a round trip counts as a circuitbreaker.Success
+// or circuitbreaker.Failure, given the response and error returned by the
+// wrapped RoundTripper.
+type Classify func(resp *http.Response, err error) circuitbreaker.Result
+
+// DefaultClassify treats network errors and 5xx responses as Failure, and
+// everything else (including 4xx) as Success, since a 4xx indicates the
+// downstream dependency is reachable and responding.
+func DefaultClassify(resp *http.Response, err error) circuitbreaker.Result {
+	if err != nil {
+		return circuitbreaker.Failure
+	}
+	if resp.StatusCode >= 500 {
+		return circuitbreaker.Failure
+	}
+	return circuitbreaker.Success
+}
+
+type roundTripper struct {
+	cb       *circuitbreaker.CircuitBreaker
+	inner    http.RoundTripper
+	classify Classify
+}
+
+// NewRoundTripper wraps inner with cb: while cb is Open, requests are
+// short-circuited with a synthetic 503 response carrying a Retry-After
+// header derived from cb's current retry/backoff countdown, instead of
+// reaching inner at all. Otherwise the request is run through
+// circuitbreaker.Execute, so SetHalfOpenMaxRequests,
+// SetHalfOpenAdmitProbability, and SetCallTimeout all apply to it exactly as
+// they would to any other Execute call, and classify decides whether the
+// result counts as a Success or Failure.
+//
+// classify, not cb's own SetIsSuccessful, is the authority for whether a
+// completed round trip is a Success or Failure - NewRoundTripper translates
+// classify's verdict into the error Execute's IsSuccessful classifier sees.
+// If cb also has a custom SetIsSuccessful configured (e.g. because it's
+// shared with non-HTTP calls), that classifier runs on top of this
+// translation, so a classify that calls a non-nil transport error a Success
+// is only respected if cb's IsSuccessful agrees once it sees that error -
+// prefer a dedicated breaker per RoundTripper to avoid that interaction.
+//
+// If classify is nil, DefaultClassify is used.
+func NewRoundTripper(cb *circuitbreaker.CircuitBreaker, inner http.RoundTripper, classify Classify) http.RoundTripper {
+	if inner == nil {
+		inner = http.DefaultTransport
+	}
+	if classify == nil {
+		classify = DefaultClassify
+	}
+	return &roundTripper{cb: cb, inner: inner, classify: classify}
+}
+
+func (rt *roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := circuitbreaker.Execute(req.Context(), rt.cb, func(ctx context.Context) (*http.Response, error) {
+		resp, err := rt.inner.RoundTrip(req.WithContext(ctx))
+		if rt.classify(resp, err) == circuitbreaker.Failure {
+			return resp, classifyFailure(err)
+		}
+		return resp, err
+	})
+
+	if errors.Is(err, circuitbreaker.ErrCircuitOpen) || errors.Is(err, circuitbreaker.ErrTooManyRequests) {
+		return circuitOpenResponse(req, rt.cb), nil
+	}
+
+	return resp, unwrapClassifyFailure(err)
+}
+
+// classifyFailureErr adapts classify's Failure verdict into the error
+// Execute's IsSuccessful classifier sees, without losing the original
+// RoundTrip error - which is often nil, e.g. a 503 response with no
+// transport-level error.
+type classifyFailureErr struct{ err error }
+
+func classifyFailure(err error) error {
+	return &classifyFailureErr{err: err}
+}
+
+func unwrapClassifyFailure(err error) error {
+	var cf *classifyFailureErr
+	if errors.As(err, &cf) {
+		return cf.err
+	}
+	return err
+}
+
+func (c *classifyFailureErr) Error() string {
+	if c.err != nil {
+		return c.err.Error()
+	}
+	return "circuitbreaker/http: classified as failure"
+}
+
+func (c *classifyFailureErr) Unwrap() error { return c.err }
+
+// circuitOpenResponse builds a synthetic 503 carrying a Retry-After header
+// computed from cb's current retry deadline.
+func circuitOpenResponse(req *http.Request, cb *circuitbreaker.CircuitBreaker) *http.Response {
+	header := http.Header{}
+	header.Set("Retry-After", retryAfterSeconds(cb))
+
+	return &http.Response{
+		Status:     "503 Service Unavailable",
+		StatusCode: http.StatusServiceUnavailable,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     header,
+		Body:       http.NoBody,
+		Request:    req,
+	}
+}
+
+func retryAfterSeconds(cb *circuitbreaker.CircuitBreaker) string {
+	seconds := int(time.Until(cb.UNSAFEGetRetryDeadline()).Seconds())
+	if seconds < 0 {
+		seconds = 0
+	}
+	return fmt.Sprintf("%d", seconds)
+}
@@ -0,0 +1,91 @@
+// Package mocktime provides a fake ITime/TimeTicker implementation that lets
+// tests fast-forward a circuit breaker's clock deterministically, instead of
+// sleeping on a real scheduler tick. It lives under internal so it can be
+// shared by every package's tests under the circuitbreaker module tree
+// (circuitbreaker_test, circuitbreaker/http_test, ...) without being part of
+// the public API.
+package mocktime
+
+import (
+	"sync"
+	"time"
+)
+
+type MockTicker struct {
+	C        chan time.Time
+	duration time.Duration
+	lastTick time.Time
+	mockTime *MockTime
+}
+
+type MockTime struct {
+	MockCurrentTime time.Time
+	tickers         []*MockTicker
+	mu              sync.Mutex
+}
+
+func NewMockTime(initialTime time.Time) *MockTime {
+	return &MockTime{
+		MockCurrentTime: initialTime,
+		tickers:         make([]*MockTicker, 0),
+	}
+}
+
+func (m *MockTime) Now() time.Time {
+	return m.MockCurrentTime
+}
+
+func (m *MockTime) NewTicker(d time.Duration) *time.Ticker {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	mockTicker := &MockTicker{
+		C:        make(chan time.Time, 10),
+		duration: d,
+		lastTick: m.MockCurrentTime,
+		mockTime: m,
+	}
+
+	m.tickers = append(m.tickers, mockTicker)
+
+	return &time.Ticker{
+		C: mockTicker.C,
+	}
+}
+
+func (m *MockTime) removeTicker(ticker *MockTicker) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, t := range m.tickers {
+		if t == ticker {
+			m.tickers = append(m.tickers[:i], m.tickers[i+1:]...)
+			return
+		}
+	}
+}
+
+func (m *MockTime) FastForward(duration time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.MockCurrentTime = m.MockCurrentTime.Add(duration)
+
+	for _, ticker := range m.tickers {
+		// Check if, given the duration, the ticker should be fired
+		next := ticker.lastTick.Add(ticker.duration)
+		if next.Before(m.MockCurrentTime) || next.Equal(m.MockCurrentTime) {
+			ticker.C <- ticker.lastTick.Add(ticker.duration)
+			ticker.lastTick = ticker.lastTick.Add(ticker.duration)
+		}
+	}
+
+	// Internally the circuit breaker sets up functions in goroutines that are triggered by ticks.
+	// There is a very small delay between a tick occurs and the callback function runs because we need to wait for Go to schedule the goroutine after the tick occurs
+	// To get around this we add a small delay(not ideal) to give the Go runtime a chance to run the goroutine
+	time.Sleep(1 * time.Millisecond)
+}
+
+func (t *MockTicker) Stop() {
+	t.mockTime.removeTicker(t)
+	close(t.C)
+}
@@ -26,6 +26,22 @@ func (r *RingBuffer[T]) Cursor() *T {
 	return &r.nodes[r.cursor]
 }
 
+// CursorIndex returns the index of the currently active node.
+func (r *RingBuffer[T]) CursorIndex() int {
+	return r.cursor
+}
+
+// Len returns the number of nodes in the ring.
+func (r *RingBuffer[T]) Len() int {
+	return len(r.nodes)
+}
+
+// At returns the node at index, independent of the cursor's current
+// position.
+func (r *RingBuffer[T]) At(index int) *T {
+	return &r.nodes[index]
+}
+
 // Traverse the ring, starting at the active node
 func (r *RingBuffer[T]) Do(f func(*T)) {
 	for i := r.cursor; i < len(r.nodes); i++ {
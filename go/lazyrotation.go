@@ -0,0 +1,75 @@
+package circuitbreaker
+
+import "time"
+
+// SetLazyRotation switches the sliding window from a wall-clock
+// cursorScheduler ticking every NodeDuration to event-driven rotation: each
+// call to Record/Execute/Do checks the active node's Expires against the
+// current time and rotates through as many nodes as needed before recording
+// the event.
+//
+// This avoids running a scheduler goroutine for idle breakers, avoids
+// skewing windows under clock/goroutine scheduling jitter, and fixes a
+// subtle bug in the wall-clock design where a burst of failures recorded
+// just before a scheduled tick lands in the active node - which
+// calculateErrorRate deliberately skips - delaying a trip decision by up to
+// one full NodeDuration.
+//
+// If not set, the circuit breaker uses the original wall-clock scheduler.
+func (b *Builder) SetLazyRotation(lazy bool) *Builder {
+	b.cb.config.LazyRotation = lazy
+	return b
+}
+
+// lazyRotateLocked rotates the buffer through every node whose window has
+// already expired, bringing it up to date with now before the caller records
+// an event against the active node. It is a no-op unless SetLazyRotation(true)
+// was configured, or once the circuit is no longer Closed (HalfOpen/Open
+// don't accumulate buffer counts). The caller must already hold cb.mu.
+func (cb *CircuitBreaker) lazyRotateLocked(now time.Time) {
+	if !cb.config.LazyRotation || cb.state != Closed {
+		return
+	}
+
+	cursor := cb.buffer.Cursor()
+	if cursor.Expires.IsZero() {
+		cursor.Reset(now.Add(cb.config.NodeDuration))
+		return
+	}
+
+	// Step the cursor forward one node at a time from its own stale Expires,
+	// not from now: rotateOnceLocked resets the new active node's Expires to
+	// its "now" argument plus NodeDuration, so passing the real now on every
+	// iteration would always produce an Expires after now and the loop would
+	// stop after a single step, no matter how long the idle gap was - leaving
+	// every other skipped node (and its now-ancient counts) still in the
+	// window. Passing the stale Expires instead advances it exactly one
+	// NodeDuration per iteration, so the loop only exits once a node's window
+	// genuinely contains now.
+	for !now.Before(cursor.Expires) && cb.state == Closed {
+		cb.rotateOnceLocked(cursor.Expires)
+		cursor = cb.buffer.Cursor()
+	}
+}
+
+// CountRecent sums FailureCount and SuccessCount across every buffer node
+// whose Expires falls within the last d, independent of node boundaries.
+// This lets callers (or a custom TripPolicy) query a truly sliding window
+// rather than one quantized to NodeDuration-sized buckets.
+func (cb *CircuitBreaker) CountRecent(d time.Duration) (failures, successes int) {
+	cb.mu.RLock()
+	defer cb.mu.RUnlock()
+
+	now := cb.time.Now()
+	cutoff := now.Add(-d)
+
+	cb.buffer.Do(func(node *BufferNode) {
+		if node.Expires.IsZero() || node.Expires.Before(cutoff) {
+			return
+		}
+		failures += node.FailureCount
+		successes += node.SuccessCount
+	})
+
+	return failures, successes
+}
@@ -0,0 +1,165 @@
+// Package metrics exports Prometheus collectors for every CircuitBreaker
+// registered in a circuitbreaker.Registry, so a service running dozens of
+// breakers (per-downstream, per-tenant) can operate them the same way it
+// operates everything else: scraped gauges and counters, not ad-hoc logging.
+package metrics
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"circuitbreaker"
+)
+
+var stateLabel = map[circuitbreaker.State]string{
+	circuitbreaker.Closed:   "closed",
+	circuitbreaker.Open:     "open",
+	circuitbreaker.HalfOpen: "half_open",
+}
+
+// Exporter is a prometheus.Collector that reports the state, error rate,
+// trial successes, and buffer occupancy of every breaker in a Registry, plus
+// cumulative state-transition counts and time-in-state histograms gathered
+// by subscribing to each breaker's StateEvents.
+type Exporter struct {
+	registry *circuitbreaker.Registry
+
+	state           *prometheus.GaugeVec
+	errorRate       *prometheus.GaugeVec
+	trialSuccesses  *prometheus.GaugeVec
+	bufferOccupancy *prometheus.GaugeVec
+	transitions     *prometheus.CounterVec
+	timeInState     *prometheus.HistogramVec
+
+	mu         sync.Mutex
+	lastChange map[string]time.Time
+	cancel     map[string]func()
+}
+
+// NewExporter creates an Exporter for registry and starts one goroutine per
+// currently-registered breaker to observe its StateEvents. Breakers
+// registered after NewExporter is called are still included in Collect's
+// gauges, but their transitions/time-in-state will only be tracked from the
+// point they are registered if the caller calls Watch for them.
+func NewExporter(registry *circuitbreaker.Registry) *Exporter {
+	e := &Exporter{
+		registry: registry,
+		state: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "circuitbreaker_state",
+			Help: "Current state of the circuit breaker (1 for the active state, 0 otherwise), labeled by state.",
+		}, []string{"name", "state"}),
+		errorRate: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "circuitbreaker_error_rate",
+			Help: "Error rate last computed for the circuit breaker's evaluation window.",
+		}, []string{"name"}),
+		trialSuccesses: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "circuitbreaker_trial_successes",
+			Help: "Consecutive successes recorded while the circuit breaker is HalfOpen.",
+		}, []string{"name"}),
+		bufferOccupancy: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "circuitbreaker_buffer_occupancy",
+			Help: "Number of events recorded in the circuit breaker's active buffer node.",
+		}, []string{"name"}),
+		transitions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "circuitbreaker_transitions_total",
+			Help: "Count of circuit breaker state transitions, labeled by the state transitioned from and to.",
+		}, []string{"name", "from", "to"}),
+		timeInState: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "circuitbreaker_time_in_state_seconds",
+			Help:    "Time spent in a state before transitioning out of it.",
+			Buckets: prometheus.ExponentialBuckets(1, 4, 8),
+		}, []string{"name", "state"}),
+		lastChange: make(map[string]time.Time),
+		cancel:     make(map[string]func()),
+	}
+
+	registry.ForEach(func(name string, cb *circuitbreaker.CircuitBreaker) {
+		e.Watch(name, cb)
+	})
+
+	return e
+}
+
+// Watch subscribes to a single breaker's StateEvents to populate the
+// transitions and timeInState metrics. NewExporter calls this for every
+// breaker already in the registry at construction time; call it directly for
+// breakers registered afterwards.
+//
+// Calling Watch again for a name already being watched (e.g. after
+// Registry.Register overwrites a breaker under the same name) cancels the
+// previous subscription first, so its goroutine doesn't leak watching a
+// breaker the registry no longer serves.
+func (e *Exporter) Watch(name string, cb *circuitbreaker.CircuitBreaker) {
+	events, cancel := cb.Subscribe()
+
+	e.mu.Lock()
+	if prevCancel, ok := e.cancel[name]; ok {
+		prevCancel()
+	}
+	e.cancel[name] = cancel
+	e.lastChange[name] = time.Now()
+	e.mu.Unlock()
+
+	go func() {
+		for event := range events {
+			e.mu.Lock()
+			since, ok := e.lastChange[name]
+			e.lastChange[name] = time.Now()
+			e.mu.Unlock()
+
+			e.transitions.WithLabelValues(name, stateLabel[event.From], stateLabel[event.To]).Inc()
+
+			if ok {
+				e.timeInState.WithLabelValues(name, stateLabel[event.From]).Observe(time.Since(since).Seconds())
+			}
+		}
+	}()
+}
+
+// Describe implements prometheus.Collector.
+func (e *Exporter) Describe(ch chan<- *prometheus.Desc) {
+	e.state.Describe(ch)
+	e.errorRate.Describe(ch)
+	e.trialSuccesses.Describe(ch)
+	e.bufferOccupancy.Describe(ch)
+	e.transitions.Describe(ch)
+	e.timeInState.Describe(ch)
+}
+
+// Collect implements prometheus.Collector, reading the latest Inspect()
+// snapshot of every breaker in the registry.
+func (e *Exporter) Collect(ch chan<- prometheus.Metric) {
+	e.state.Reset()
+	e.errorRate.Reset()
+	e.trialSuccesses.Reset()
+	e.bufferOccupancy.Reset()
+
+	e.registry.ForEach(func(name string, cb *circuitbreaker.CircuitBreaker) {
+		status := cb.Inspect()
+
+		for state, label := range stateLabel {
+			value := 0.0
+			if status.State == state {
+				value = 1.0
+			}
+			e.state.WithLabelValues(name, label).Set(value)
+		}
+
+		e.errorRate.WithLabelValues(name).Set(status.ErrorRate)
+		e.trialSuccesses.WithLabelValues(name).Set(float64(status.TrialSuccesses))
+
+		if status.ActiveNode != nil {
+			occupancy := status.ActiveNode.SuccessCount + status.ActiveNode.FailureCount
+			e.bufferOccupancy.WithLabelValues(name).Set(float64(occupancy))
+		}
+	})
+
+	e.state.Collect(ch)
+	e.errorRate.Collect(ch)
+	e.trialSuccesses.Collect(ch)
+	e.bufferOccupancy.Collect(ch)
+	e.transitions.Collect(ch)
+	e.timeInState.Collect(ch)
+}
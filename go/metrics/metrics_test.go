@@ -0,0 +1,137 @@
+package metrics_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+
+	"circuitbreaker"
+	"circuitbreaker/metrics"
+)
+
+func TestExporterCollectReportsStateAndErrorRate(t *testing.T) {
+	registry := circuitbreaker.NewRegistry()
+	cb := circuitbreaker.
+		New().
+		SetName("payments").
+		SetEvalWindow(1, 60).
+		SetTripPolicy(circuitbreaker.ConsecutiveFailuresPolicy(1)).
+		Build()
+	registry.Register("payments", cb)
+
+	exporter := metrics.NewExporter(registry)
+
+	cb.Record(circuitbreaker.Failure)
+	if cb.GetState() != circuitbreaker.Open {
+		t.Fatalf("expected the circuit to be Open after a tripping failure, got %v", cb.GetState())
+	}
+
+	families := gather(t, exporter)
+
+	openValue := gaugeValue(t, families, "circuitbreaker_state", map[string]string{"name": "payments", "state": "open"})
+	if openValue != 1 {
+		t.Fatalf(`expected circuitbreaker_state{state="open"} to be 1, got %v`, openValue)
+	}
+
+	closedValue := gaugeValue(t, families, "circuitbreaker_state", map[string]string{"name": "payments", "state": "closed"})
+	if closedValue != 0 {
+		t.Fatalf(`expected circuitbreaker_state{state="closed"} to be 0, got %v`, closedValue)
+	}
+}
+
+func TestExporterWatchRecordsTransitions(t *testing.T) {
+	registry := circuitbreaker.NewRegistry()
+	cb := circuitbreaker.
+		New().
+		SetName("payments").
+		SetEvalWindow(1, 60).
+		SetTripPolicy(circuitbreaker.ConsecutiveFailuresPolicy(1)).
+		Build()
+	registry.Register("payments", cb)
+
+	exporter := metrics.NewExporter(registry)
+
+	cb.Record(circuitbreaker.Failure)
+	if cb.GetState() != circuitbreaker.Open {
+		t.Fatalf("expected the circuit to be Open after a tripping failure, got %v", cb.GetState())
+	}
+
+	// Watch's subscriber goroutine records the transition asynchronously, so
+	// poll briefly rather than asserting immediately after Record returns.
+	deadline := time.Now().Add(time.Second)
+	for {
+		families := gather(t, exporter)
+		if count := counterValue(families, "circuitbreaker_transitions_total", map[string]string{"name": "payments", "from": "closed", "to": "open"}); count > 0 {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected Watch's subscriber goroutine to record the closed->open transition")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func gather(t *testing.T, exporter *metrics.Exporter) []*dto.MetricFamily {
+	t.Helper()
+
+	reg := prometheus.NewRegistry()
+	if err := reg.Register(exporter); err != nil {
+		t.Fatalf("failed to register exporter: %v", err)
+	}
+
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("failed to gather metrics: %v", err)
+	}
+	return families
+}
+
+func gaugeValue(t *testing.T, families []*dto.MetricFamily, name string, labels map[string]string) float64 {
+	t.Helper()
+	m := findMetric(t, families, name, labels)
+	return m.GetGauge().GetValue()
+}
+
+func counterValue(families []*dto.MetricFamily, name string, labels map[string]string) float64 {
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, m := range family.GetMetric() {
+			if labelsMatch(m, labels) {
+				return m.GetCounter().GetValue()
+			}
+		}
+	}
+	return 0
+}
+
+func findMetric(t *testing.T, families []*dto.MetricFamily, name string, labels map[string]string) *dto.Metric {
+	t.Helper()
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, m := range family.GetMetric() {
+			if labelsMatch(m, labels) {
+				return m
+			}
+		}
+	}
+	t.Fatalf("no metric named %q with labels %v found", name, labels)
+	return nil
+}
+
+func labelsMatch(m *dto.Metric, labels map[string]string) bool {
+	if len(m.GetLabel()) != len(labels) {
+		return false
+	}
+	for _, label := range m.GetLabel() {
+		if labels[label.GetName()] != label.GetValue() {
+			return false
+		}
+	}
+	return true
+}
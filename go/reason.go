@@ -0,0 +1,56 @@
+package circuitbreaker
+
+import "time"
+
+// StateChangeReason explains why a state transition happened, letting
+// subscribers render or log richer detail than the bare from/to states
+// (e.g. an audible/ANSI-flash only for ThresholdExceeded, not for a routine
+// RetryElapsed promotion).
+//
+// This only covers the structured-notification half of chunk1-4's original
+// ask; the generic CircuitBreaker[T] re-parameterization it also requested
+// was deliberately scoped out (see the chunk1-4 commit message) and has not
+// been confirmed as acceptable with whoever filed it - don't treat chunk1-4
+// as fully delivered on the strength of this type alone.
+//
+// Restated at the second review round: still unresolved, still needs
+// explicit sign-off from whoever filed chunk1-4 before this is closed out.
+type StateChangeReason int
+
+const (
+	// ThresholdExceeded: a Closed circuit tripped to Open because its
+	// TripPolicy reported the window should trip.
+	ThresholdExceeded StateChangeReason = iota
+	// TrialSuccessesMet: a HalfOpen circuit closed after enough consecutive
+	// trial successes.
+	TrialSuccessesMet
+	// TrialFailure: a HalfOpen circuit reopened after a trial call failed.
+	TrialFailure
+	// RetryElapsed: an Open circuit moved to HalfOpen because its
+	// Backoff-scheduled retry timer fired.
+	RetryElapsed
+	// HealthProbeRecovered: an Open circuit moved to HalfOpen because its
+	// configured HealthProbe succeeded.
+	HealthProbeRecovered
+)
+
+// SetOnStateChange registers a callback invoked with the reason behind each
+// state transition, in addition to (not instead of) any callback registered
+// via OnStateChange. The callback must not block and must not call back into
+// the breaker it was registered on.
+func (b *Builder) SetOnStateChange(fn func(from, to State, at time.Time, reason StateChangeReason)) *Builder {
+	b.cb.events.onChangeWithReason = append(b.cb.events.onChangeWithReason, fn)
+	return b
+}
+
+// publishStateChangeReason publishes both the StateEvent (for OnStateChange
+// and Subscribe) and the richer SetOnStateChange notification. The caller
+// must already hold cb.mu.
+func (cb *CircuitBreaker) publishStateChangeReason(from, to State, reason StateChangeReason) {
+	at := cb.time.Now()
+	cb.publishStateChange(cb.name, from, to)
+
+	for _, fn := range cb.events.onChangeWithReason {
+		fn(from, to, at, reason)
+	}
+}
@@ -0,0 +1,54 @@
+package circuitbreaker
+
+import "sync"
+
+// Registry stores named CircuitBreaker instances so a service running many
+// of them - one per downstream dependency, one per tenant - can look them up,
+// iterate over them for reporting, or hand them to a metrics exporter
+// without threading every instance through the call stack by hand.
+type Registry struct {
+	mu       sync.RWMutex
+	breakers map[string]*CircuitBreaker
+}
+
+// NewRegistry creates an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		breakers: make(map[string]*CircuitBreaker),
+	}
+}
+
+// Register stores cb under name, overwriting any breaker previously
+// registered under the same name.
+func (r *Registry) Register(name string, cb *CircuitBreaker) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.breakers[name] = cb
+}
+
+// Get returns the breaker registered under name, if any.
+func (r *Registry) Get(name string) (*CircuitBreaker, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	cb, ok := r.breakers[name]
+	return cb, ok
+}
+
+// ForEach calls fn for every registered breaker. The order of iteration is
+// not guaranteed.
+func (r *Registry) ForEach(fn func(name string, cb *CircuitBreaker)) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for name, cb := range r.breakers {
+		fn(name, cb)
+	}
+}
+
+// SetName configures the name this circuit breaker reports in published
+// StateEvents and, once registered, in Registry/metrics labels.
+//
+// If not set, the breaker's name defaults to the empty string.
+func (b *Builder) SetName(name string) *Builder {
+	b.cb.name = name
+	return b
+}
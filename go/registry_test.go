@@ -0,0 +1,48 @@
+package circuitbreaker_test
+
+import (
+	"testing"
+
+	"circuitbreaker"
+)
+
+func TestRegistryRegisterGetForEach(t *testing.T) {
+	r := circuitbreaker.NewRegistry()
+
+	if _, ok := r.Get("payments"); ok {
+		t.Fatal("expected Get on an empty registry to report not found")
+	}
+
+	payments := circuitbreaker.New().SetName("payments").Build()
+	inventory := circuitbreaker.New().SetName("inventory").Build()
+
+	r.Register("payments", payments)
+	r.Register("inventory", inventory)
+
+	got, ok := r.Get("payments")
+	assert(t, ok, true)
+	assert(t, got, payments)
+
+	seen := map[string]*circuitbreaker.CircuitBreaker{}
+	r.ForEach(func(name string, cb *circuitbreaker.CircuitBreaker) {
+		seen[name] = cb
+	})
+
+	if len(seen) != 2 || seen["payments"] != payments || seen["inventory"] != inventory {
+		t.Fatalf("expected ForEach to visit both registered breakers, got %v", seen)
+	}
+}
+
+func TestRegistryRegisterOverwrites(t *testing.T) {
+	r := circuitbreaker.NewRegistry()
+
+	first := circuitbreaker.New().SetName("payments").Build()
+	second := circuitbreaker.New().SetName("payments").Build()
+
+	r.Register("payments", first)
+	r.Register("payments", second)
+
+	got, ok := r.Get("payments")
+	assert(t, ok, true)
+	assert(t, got, second)
+}
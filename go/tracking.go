@@ -0,0 +1,194 @@
+package circuitbreaker
+
+import (
+	"sync"
+	"time"
+
+	"circuitbreaker/internal/ringbuffer"
+)
+
+// Tracking owns the sliding-window ring buffer, failure/success accounting,
+// error-rate calculation, and Closed/Open/HalfOpen state transitions that
+// back a CircuitBreaker - but none of the scheduling (the cursor/retry
+// schedulers) or admission control layered on top of it by CircuitBreaker.
+//
+// It is exported so other integrations - a go-redis hook, a database/sql
+// driver, a custom RPC client - can reuse the same sliding-window accounting
+// without inheriting CircuitBreaker's opinionated Record/Execute behavior.
+// Callers own the timing: call Rotate periodically (or lazily before each
+// request) and MoveToHalfOpen once a retry timeout has elapsed.
+type Tracking struct {
+	mu                     sync.Mutex
+	state                  State
+	buffer                 *ringbuffer.RingBuffer[BufferNode]
+	errorRate              float64
+	trialSuccesses         int
+	consecutiveFailures    int
+	tripPolicy             TripPolicy
+	nodeDuration           time.Duration
+	trialSuccessesRequired int
+}
+
+// NewTracking creates a Tracking primitive with the given number of buffer
+// nodes (each spanning nodeDuration), gated by tripPolicy, requiring
+// trialSuccessesRequired consecutive successes to close a HalfOpen circuit.
+func NewTracking(nodes int, nodeDuration time.Duration, tripPolicy TripPolicy, trialSuccessesRequired int) *Tracking {
+	return &Tracking{
+		state:                  Closed,
+		buffer:                 ringbuffer.New[BufferNode](nodes + 1),
+		tripPolicy:             tripPolicy,
+		nodeDuration:           nodeDuration,
+		trialSuccessesRequired: trialSuccessesRequired,
+	}
+}
+
+// State returns the current Closed/Open/HalfOpen state.
+func (tr *Tracking) State() State {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	return tr.state
+}
+
+// OnRequest reports whether a caller may proceed given the current state,
+// returning ErrCircuitOpen otherwise. It applies no HalfOpen admission
+// control (concurrency caps, probabilistic admission) - that is a concern
+// layered on top by CircuitBreaker.admitLocked.
+func (tr *Tracking) OnRequest() error {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+
+	if tr.state == Open {
+		return ErrCircuitOpen
+	}
+	return nil
+}
+
+// OnSuccess records a successful call. While HalfOpen, it counts towards the
+// consecutive trial successes required to close the circuit again.
+func (tr *Tracking) OnSuccess() {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+
+	if tr.state == HalfOpen {
+		tr.trialSuccesses++
+		if tr.trialSuccesses >= tr.trialSuccessesRequired {
+			tr.state = Closed
+			tr.trialSuccesses = 0
+		}
+		return
+	}
+
+	if tr.state == Closed {
+		tr.buffer.Cursor().SuccessCount++
+		tr.consecutiveFailures = 0
+	}
+}
+
+// OnFailure records a failed call. While HalfOpen, any failure reopens the
+// circuit immediately.
+func (tr *Tracking) OnFailure() {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+
+	if tr.state == HalfOpen {
+		tr.state = Open
+		tr.trialSuccesses = 0
+		return
+	}
+
+	if tr.state == Closed {
+		tr.buffer.Cursor().FailureCount++
+		tr.consecutiveFailures++
+	}
+}
+
+// MoveToHalfOpen transitions an Open circuit to HalfOpen. It is a no-op if
+// the circuit is not currently Open. Callers drive this once their own retry
+// timeout (or health probe, see SetHealthProbe) decides the circuit should
+// admit trial traffic again.
+func (tr *Tracking) MoveToHalfOpen() {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+
+	if tr.state == Open {
+		tr.state = HalfOpen
+	}
+}
+
+// Rotate advances the ring buffer to a new active node expiring at
+// now.Add(nodeDuration), recalculates the error rate, and trips a Closed
+// circuit to Open if the configured TripPolicy says to.
+func (tr *Tracking) Rotate(now time.Time) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+
+	tr.buffer.Next()
+	tr.buffer.Cursor().Reset(now.Add(tr.nodeDuration))
+	tr.errorRate = tr.calculateErrorRateLocked()
+
+	if tr.state == Closed && tr.tripPolicy.ShouldTrip(tr.snapshotLocked()) {
+		tr.state = Open
+		tr.clearBufferLocked()
+		tr.errorRate = 0.00
+	}
+}
+
+// Snapshot returns a point-in-time view of the ring buffer and counters,
+// suitable for reporting or for a custom TripPolicy evaluated outside Rotate.
+func (tr *Tracking) Snapshot() BufferSnapshot {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	return tr.snapshotLocked()
+}
+
+// ErrorRate returns the error rate computed at the last Rotate call.
+func (tr *Tracking) ErrorRate() float64 {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	return tr.errorRate
+}
+
+func (tr *Tracking) snapshotLocked() BufferSnapshot {
+	var nodes []BufferNode
+	skippedActiveNode := false
+
+	tr.buffer.Do(func(node *BufferNode) {
+		if !skippedActiveNode {
+			skippedActiveNode = true
+			return
+		}
+		nodes = append(nodes, *node)
+	})
+
+	return BufferSnapshot{
+		Nodes:               nodes,
+		ActiveNode:          *tr.buffer.Cursor(),
+		ConsecutiveFailures: tr.consecutiveFailures,
+	}
+}
+
+func (tr *Tracking) calculateErrorRateLocked() float64 {
+	snapshot := tr.snapshotLocked()
+
+	failures := 0
+	total := 0
+	for _, node := range snapshot.Nodes {
+		failures += node.FailureCount
+		total += node.FailureCount + node.SuccessCount
+	}
+
+	if total == 0 {
+		return 0
+	}
+
+	return float64(failures) / float64(total) * 100
+}
+
+func (tr *Tracking) clearBufferLocked() {
+	tr.buffer.Do(func(node *BufferNode) {
+		node.Expires = time.Time{}
+		node.FailureCount = 0
+		node.SuccessCount = 0
+		node.TimeoutCount = 0
+	})
+}
@@ -0,0 +1,37 @@
+package circuitbreaker_test
+
+import (
+	"testing"
+	"time"
+
+	"circuitbreaker"
+)
+
+func TestTrackingTripsAndRecovers(t *testing.T) {
+	now := time.Now()
+	tr := circuitbreaker.NewTracking(2, time.Minute, circuitbreaker.ErrorRatePolicy{Threshold: 10.0, MinEvalSize: 10}, 2)
+
+	for i := 0; i < 20; i++ {
+		tr.OnSuccess()
+	}
+	now = now.Add(time.Minute)
+	tr.Rotate(now)
+	assert(t, circuitbreaker.Closed, tr.State())
+
+	for i := 0; i < 5; i++ {
+		tr.OnFailure()
+	}
+	now = now.Add(time.Minute)
+	tr.Rotate(now)
+	assert(t, circuitbreaker.Open, tr.State())
+
+	assert(t, tr.OnRequest(), circuitbreaker.ErrCircuitOpen)
+
+	tr.MoveToHalfOpen()
+	assert(t, circuitbreaker.HalfOpen, tr.State())
+
+	tr.OnSuccess()
+	assert(t, circuitbreaker.HalfOpen, tr.State())
+	tr.OnSuccess()
+	assert(t, circuitbreaker.Closed, tr.State())
+}
@@ -0,0 +1,143 @@
+package circuitbreaker
+
+import "math"
+
+// BufferSnapshot is a read-only view of the ring buffer passed to a
+// TripPolicy each time the circuit breaker evaluates whether to trip from
+// Closed to Open.
+type BufferSnapshot struct {
+	// Nodes holds the completed (non-active) buffer nodes, in the same
+	// traversal order as calculateErrorRate: oldest to most recent.
+	Nodes []BufferNode
+	// ActiveNode is the node currently accumulating counts. It is excluded
+	// from Nodes because it has not finished its window yet.
+	ActiveNode BufferNode
+	// ConsecutiveFailures is the number of Failure results recorded back to
+	// back, irrespective of buffer node boundaries. It resets to 0 on the
+	// next Success.
+	ConsecutiveFailures int
+}
+
+// TripPolicy decides whether a Closed circuit breaker should trip to Open,
+// given the current state of its sliding window. Implementations must be
+// safe to call repeatedly with no side effects; the breaker calls ShouldTrip
+// while holding its internal lock.
+type TripPolicy interface {
+	ShouldTrip(snapshot BufferSnapshot) bool
+}
+
+// ErrorRatePolicy trips when the percentage of failures across Nodes exceeds
+// Threshold, but only once at least MinEvalSize events have been observed.
+// This is the circuit breaker's original, default trip behavior.
+type ErrorRatePolicy struct {
+	Threshold   float64
+	MinEvalSize int
+}
+
+func (p ErrorRatePolicy) ShouldTrip(snapshot BufferSnapshot) bool {
+	failures := 0
+	total := 0
+
+	for _, node := range snapshot.Nodes {
+		failures += node.FailureCount
+		total += node.FailureCount + node.SuccessCount
+	}
+
+	if total < p.MinEvalSize || total == 0 {
+		return false
+	}
+
+	errorRate := math.Round((float64(failures)/float64(total))*100*100) / 100
+	return errorRate > p.Threshold
+}
+
+// ConsecutiveFailuresPolicy trips once N Failure results have been recorded
+// back to back, regardless of buffer node boundaries. It mirrors overcurrent's
+// NewConsecutiveFailureTripCondition.
+type ConsecutiveFailuresPolicy int
+
+func (n ConsecutiveFailuresPolicy) ShouldTrip(snapshot BufferSnapshot) bool {
+	return snapshot.ConsecutiveFailures >= int(n)
+}
+
+// TotalFailuresInWindowPolicy trips once N failures have been observed across
+// the completed nodes of the evaluation window, with no regard to success
+// count or rate.
+type TotalFailuresInWindowPolicy int
+
+func (n TotalFailuresInWindowPolicy) ShouldTrip(snapshot BufferSnapshot) bool {
+	failures := 0
+	for _, node := range snapshot.Nodes {
+		failures += node.FailureCount
+	}
+	return failures >= int(n)
+}
+
+// CompositeMode selects how a CompositePolicy combines its child policies.
+type CompositeMode int
+
+const (
+	// CompositeAny trips as soon as any child policy trips (logical OR).
+	CompositeAny CompositeMode = iota
+	// CompositeAll trips only once every child policy trips (logical AND).
+	CompositeAll
+)
+
+// CompositePolicy combines multiple TripPolicies under a single ShouldTrip
+// decision, ORed or ANDed together depending on Mode.
+type CompositePolicy struct {
+	Policies []TripPolicy
+	Mode     CompositeMode
+}
+
+func (c CompositePolicy) ShouldTrip(snapshot BufferSnapshot) bool {
+	if len(c.Policies) == 0 {
+		return false
+	}
+
+	for _, p := range c.Policies {
+		tripped := p.ShouldTrip(snapshot)
+
+		if c.Mode == CompositeAny && tripped {
+			return true
+		}
+
+		if c.Mode == CompositeAll && !tripped {
+			return false
+		}
+	}
+
+	return c.Mode == CompositeAll
+}
+
+// SetTripPolicy overrides the strategy used to decide whether a Closed
+// circuit breaker should trip to Open. It replaces the single-knob
+// SetErrorThreshold/SetMinEvalSize pair with a composable TripPolicy.
+//
+// If not set, the default policy is ErrorRatePolicy configured from
+// SetErrorThreshold/SetMinEvalSize (or their defaults).
+func (b *Builder) SetTripPolicy(policy TripPolicy) *Builder {
+	b.cb.tripPolicy = policy
+	return b
+}
+
+// snapshotBufferLocked builds a BufferSnapshot from the current ring buffer.
+// The caller must already hold cb.mu.
+func (cb *CircuitBreaker) snapshotBufferLocked() BufferSnapshot {
+	var nodes []BufferNode
+	skippedActiveNode := false
+
+	cb.buffer.Do(func(node *BufferNode) {
+		if !skippedActiveNode {
+			skippedActiveNode = true
+			return
+		}
+		nodes = append(nodes, *node)
+	})
+
+	return BufferSnapshot{
+		Nodes:               nodes,
+		ActiveNode:          *cb.buffer.Cursor(),
+		ConsecutiveFailures: cb.consecutiveFailures,
+	}
+}